@@ -0,0 +1,28 @@
+package main
+
+import (
+	"crypto/tls"
+	"strings"
+
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// newACMETLSConfig returns a TLS config that obtains and renews certificates
+// for domains from Let's Encrypt, caching them in cacheDir. Certificates are
+// validated with the TLS-ALPN-01 challenge, so the server must be reachable
+// on port 443.
+func newACMETLSConfig(domains string, cacheDir string, email string) *tls.Config {
+	var hosts []string
+	for _, domain := range strings.Split(domains, ",") {
+		if domain = strings.TrimSpace(domain); domain != "" {
+			hosts = append(hosts, domain)
+		}
+	}
+	manager := &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		HostPolicy: autocert.HostWhitelist(hosts...),
+		Cache:      autocert.DirCache(cacheDir),
+		Email:      email,
+	}
+	return manager.TLSConfig()
+}
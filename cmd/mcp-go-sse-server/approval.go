@@ -0,0 +1,185 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	mcpserver "mcp-go-sse-server/pkg/server"
+)
+
+// pendingApproval is a tool call held until an operator decides on it.
+type pendingApproval struct {
+	ID          string    `json:"id"`
+	Tool        string    `json:"tool"`
+	Arguments   any       `json:"arguments,omitempty"`
+	SessionID   string    `json:"session_id,omitempty"`
+	Principal   string    `json:"principal,omitempty"`
+	RequestedAt time.Time `json:"requested_at"`
+	decision    chan bool
+}
+
+// approvalGate holds calls of the gated tools until they are approved or
+// denied through the admin endpoints, or the timeout expires.
+type approvalGate struct {
+	tools      map[string]bool
+	timeout    time.Duration
+	adminToken string
+	redactor   *mcpserver.Redactor
+
+	mu      sync.Mutex
+	pending map[string]*pendingApproval
+	// shutdown is closed when the server shuts down, failing held calls.
+	shutdown  chan struct{}
+	drainOnce sync.Once
+}
+
+// newApprovalGate gates the comma-separated tools. Arguments shown to
+// operators are masked by redactor, which may be nil.
+func newApprovalGate(tools string, timeout time.Duration, adminToken string, redactor *mcpserver.Redactor) *approvalGate {
+	g := &approvalGate{
+		tools:      map[string]bool{},
+		timeout:    timeout,
+		adminToken: adminToken,
+		redactor:   redactor,
+		pending:    map[string]*pendingApproval{},
+		shutdown:   make(chan struct{}),
+	}
+	for _, tool := range strings.Split(tools, ",") {
+		if tool = strings.TrimSpace(tool); tool != "" {
+			g.tools[tool] = true
+		}
+	}
+	return g
+}
+
+// middleware holds calls of gated tools. A denied or expired call returns a
+// tool error without running the tool.
+func (g *approvalGate) middleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if !g.tools[request.Params.Name] && !g.tools[ALL_TOOLS] {
+			return next(ctx, request)
+		}
+		select {
+		case <-g.shutdown:
+			return shutdownToolResult(request.Params.Name), nil
+		default:
+		}
+		approval := &pendingApproval{
+			ID:          uuid.NewString(),
+			Tool:        request.Params.Name,
+			Arguments:   g.redactor.RedactValue(request.Params.Arguments),
+			RequestedAt: time.Now(),
+			decision:    make(chan bool, 1),
+		}
+		if session := server.ClientSessionFromContext(ctx); session != nil {
+			approval.SessionID = session.SessionID()
+		}
+		if claims, ok := mcpserver.ClaimsFromContext(ctx); ok {
+			approval.Principal, _ = claims["sub"].(string)
+		}
+		g.mu.Lock()
+		g.pending[approval.ID] = approval
+		g.mu.Unlock()
+		defer func() {
+			g.mu.Lock()
+			delete(g.pending, approval.ID)
+			g.mu.Unlock()
+		}()
+		log.Printf("Tool call %s awaits approval: %s", approval.ID, approval.Tool)
+
+		timer := time.NewTimer(g.timeout)
+		defer timer.Stop()
+		select {
+		case approved := <-approval.decision:
+			if !approved {
+				return mcp.NewToolResultError(fmt.Sprintf("Call of tool %s was denied by an operator.", approval.Tool)), nil
+			}
+			return next(ctx, request)
+		case <-g.shutdown:
+			return shutdownToolResult(approval.Tool), nil
+		case <-timer.C:
+			return mcp.NewToolResultError(fmt.Sprintf("Call of tool %s was not approved within %s.", approval.Tool, g.timeout)), nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// drain fails the held calls, and every gated call made from now on, so they
+// return a result before the streams close. A nil gate has nothing to drain.
+func (g *approvalGate) drain() {
+	if g == nil {
+		return
+	}
+	g.drainOnce.Do(func() { close(g.shutdown) })
+}
+
+func shutdownToolResult(tool string) *mcp.CallToolResult {
+	return mcp.NewToolResultError(fmt.Sprintf("Call of tool %s was not run: the server is shutting down.", tool))
+}
+
+// handle registers the approval endpoints under basePath. A nil gate
+// registers nothing.
+func (g *approvalGate) handle(mux *http.ServeMux, basePath string) {
+	if g == nil {
+		return
+	}
+	mux.HandleFunc("GET "+basePath+"/admin/approvals", g.handleList)
+	mux.HandleFunc("POST "+basePath+"/admin/approvals/{id}/{decision}", g.handleDecision)
+}
+
+// handleList reports the calls awaiting a decision.
+func (g *approvalGate) handleList(w http.ResponseWriter, r *http.Request) {
+	if !authorizeAdmin(w, r, g.adminToken) {
+		return
+	}
+	g.mu.Lock()
+	pending := make([]*pendingApproval, 0, len(g.pending))
+	for _, approval := range g.pending {
+		pending = append(pending, approval)
+	}
+	g.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"pending": pending,
+	})
+}
+
+// handleDecision approves or denies a held call.
+func (g *approvalGate) handleDecision(w http.ResponseWriter, r *http.Request) {
+	if !authorizeAdmin(w, r, g.adminToken) {
+		return
+	}
+	var approved bool
+	switch r.PathValue("decision") {
+	case "approve":
+		approved = true
+	case "deny":
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	g.mu.Lock()
+	approval, ok := g.pending[r.PathValue("id")]
+	if ok {
+		delete(g.pending, approval.ID)
+	}
+	g.mu.Unlock()
+	if !ok {
+		http.Error(w, "No pending tool call with this ID", http.StatusNotFound)
+		return
+	}
+	approval.decision <- approved
+	log.Printf("Tool call %s %sd", approval.ID, r.PathValue("decision"))
+	w.WriteHeader(http.StatusNoContent)
+}
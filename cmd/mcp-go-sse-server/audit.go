@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	mcpserver "mcp-go-sse-server/pkg/server"
+)
+
+// auditLog appends one JSON line per tool call to a file. It covers every
+// transport, including calls refused by --tool-policy.
+type auditLog struct {
+	logger   *slog.Logger
+	redactor *mcpserver.Redactor
+}
+
+// newAuditLog opens path for appending, creating it with owner-only
+// permissions. "-" writes to stderr. Arguments and errors are masked by
+// redactor, and records are encrypted with ring; both may be nil.
+func newAuditLog(path string, redactor *mcpserver.Redactor, ring *keyring) (*auditLog, error) {
+	var out io.Writer = os.Stderr
+	if path != "-" {
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open audit log: %w", err)
+		}
+		out = file
+	}
+	if ring != nil {
+		out = &encryptingWriter{w: out, ring: ring}
+	}
+	return &auditLog{logger: slog.New(slog.NewJSONHandler(out, nil)), redactor: redactor}, nil
+}
+
+func (a *auditLog) middleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		start := time.Now()
+		result, err := next(ctx, request)
+
+		attrs := []any{
+			slog.String("tool", request.Params.Name),
+			slog.Any("arguments", a.redactor.RedactValue(request.Params.Arguments)),
+			slog.Duration("duration", time.Since(start)),
+		}
+		if session := server.ClientSessionFromContext(ctx); session != nil {
+			attrs = append(attrs, slog.String("session_id", session.SessionID()))
+		}
+		if claims, ok := mcpserver.ClaimsFromContext(ctx); ok {
+			if subject, ok := claims["sub"].(string); ok {
+				attrs = append(attrs, slog.String("principal", subject))
+			}
+		}
+		if result != nil {
+			if encoded, marshalErr := json.Marshal(result); marshalErr == nil {
+				attrs = append(attrs, slog.Int("result_bytes", len(encoded)))
+			}
+			attrs = append(attrs, slog.Bool("is_error", result.IsError))
+		}
+		if err != nil {
+			attrs = append(attrs, slog.String("error", a.redactor.Redact(err.Error())))
+		}
+		a.logger.Info("tool call", attrs...)
+		return result, err
+	}
+}
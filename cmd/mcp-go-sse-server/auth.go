@@ -0,0 +1,207 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	mcpserver "mcp-go-sse-server/pkg/server"
+)
+
+// API_KEY_QUERY_PARAM carries the API key for clients that cannot set an
+// Authorization header.
+const API_KEY_QUERY_PARAM = "api_key"
+
+// authenticator guards the transport endpoints with API keys, HTTP Basic
+// credentials, JWTs, or any combination. Operational endpoints such as
+// /healthz stay open.
+type authenticator struct {
+	apiKeys [][]byte
+	// basicCredentials holds "user:password" pairs.
+	basicCredentials [][]byte
+	jwt              *jwtValidator
+	// oauth, when set, is advertised in the WWW-Authenticate challenge.
+	oauth *oauthProvider
+}
+
+// newAuthenticator collects API keys from a comma-separated list and a keys
+// file, and Basic credentials from a user:password pair and a credentials
+// file. It returns nil when no credential and no JWT validator is configured.
+func newAuthenticator(keys string, keysFile string, basicAuth string, basicAuthFile string, jwt *jwtValidator) (*authenticator, error) {
+	a := &authenticator{jwt: jwt}
+	for _, key := range strings.Split(keys, ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			a.apiKeys = append(a.apiKeys, []byte(key))
+		}
+	}
+	if keysFile != "" {
+		fileKeys, err := readCredentialsFile(keysFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read API keys file: %w", err)
+		}
+		a.apiKeys = append(a.apiKeys, fileKeys...)
+	}
+	if basicAuth != "" {
+		a.basicCredentials = append(a.basicCredentials, []byte(basicAuth))
+	}
+	if basicAuthFile != "" {
+		fileCredentials, err := readCredentialsFile(basicAuthFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read basic auth file: %w", err)
+		}
+		a.basicCredentials = append(a.basicCredentials, fileCredentials...)
+	}
+	for _, credentials := range a.basicCredentials {
+		if user, _, ok := strings.Cut(string(credentials), ":"); !ok || user == "" {
+			return nil, fmt.Errorf("basic auth credentials must be user:password")
+		}
+	}
+	if len(a.apiKeys) == 0 && len(a.basicCredentials) == 0 && a.jwt == nil {
+		return nil, nil
+	}
+	return a, nil
+}
+
+// readCredentialsFile returns the lines of path, ignoring blank lines and
+// # comments.
+func readCredentialsFile(path string) ([][]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	var credentials [][]byte
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			credentials = append(credentials, []byte(line))
+		}
+	}
+	return credentials, scanner.Err()
+}
+
+// validAPIKey compares key against every configured key in constant time.
+func (a *authenticator) validAPIKey(key string) bool {
+	return key != "" && matchAny([]byte(key), a.apiKeys)
+}
+
+// apiKeyPrincipal names the holder of an API key without revealing it, as
+// key- followed by the first 8 hex digits of its SHA-256.
+func apiKeyPrincipal(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return "key-" + hex.EncodeToString(sum[:4])
+}
+
+// validBasic compares user:password against every configured pair in
+// constant time.
+func (a *authenticator) validBasic(user string, password string) bool {
+	return user != "" && matchAny([]byte(user+":"+password), a.basicCredentials)
+}
+
+// matchAny reports whether value equals one of candidates, comparing against
+// all of them so the timing does not reveal which one matched.
+func matchAny(value []byte, candidates [][]byte) bool {
+	valid := 0
+	for _, candidate := range candidates {
+		valid |= subtle.ConstantTimeCompare(value, candidate)
+	}
+	return valid == 1
+}
+
+// authenticate checks an Authorization header value: Basic credentials, or a
+// bearer token that is an API key or a valid JWT. The claims of a JWT, or a
+// "sub" claim naming the Basic user or API key, are attached to the returned
+// context for tool handlers.
+func (a *authenticator) authenticate(ctx context.Context, authorization string) (context.Context, bool) {
+	scheme, credentials, _ := strings.Cut(authorization, " ")
+	credentials = strings.TrimSpace(credentials)
+	if strings.EqualFold(scheme, "Basic") {
+		decoded, err := base64.StdEncoding.DecodeString(credentials)
+		if err != nil {
+			return ctx, false
+		}
+		user, password, _ := strings.Cut(string(decoded), ":")
+		if !a.validBasic(user, password) {
+			return ctx, false
+		}
+		return mcpserver.WithClaims(ctx, map[string]any{"sub": user}), true
+	}
+	if !strings.EqualFold(scheme, "Bearer") {
+		return ctx, false
+	}
+	token := credentials
+	if token == "" {
+		return ctx, false
+	}
+	if a.validAPIKey(token) {
+		return mcpserver.WithClaims(ctx, map[string]any{"sub": apiKeyPrincipal(token)}), true
+	}
+	if a.jwt != nil {
+		claims, err := a.jwt.validate(ctx, token)
+		if err == nil {
+			return mcpserver.WithClaims(ctx, claims), true
+		}
+	}
+	return ctx, false
+}
+
+// middleware rejects requests without valid credentials with 401. A nil
+// authenticator lets everything through.
+func (a *authenticator) middleware(next http.Handler) http.Handler {
+	if a == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization := r.Header.Get("Authorization")
+		if authorization == "" && r.URL.Query().Has(API_KEY_QUERY_PARAM) {
+			authorization = "Bearer " + r.URL.Query().Get(API_KEY_QUERY_PARAM)
+		}
+		ctx, ok := a.authenticate(r.Context(), authorization)
+		if !ok {
+			if len(a.basicCredentials) > 0 {
+				w.Header().Add("WWW-Authenticate", `Basic realm="mcp", charset="UTF-8"`)
+			}
+			if len(a.apiKeys) > 0 || a.jwt != nil {
+				w.Header().Add("WWW-Authenticate", a.oauth.wwwAuthenticate())
+			}
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// streamInterceptor applies the same check to gRPC streams, reading the
+// credentials from the authorization metadata.
+func (a *authenticator) streamInterceptor(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	md, _ := metadata.FromIncomingContext(stream.Context())
+	for _, value := range md.Get("authorization") {
+		if ctx, ok := a.authenticate(stream.Context(), value); ok {
+			return handler(srv, &authenticatedStream{ServerStream: stream, ctx: ctx})
+		}
+	}
+	return status.Error(codes.Unauthenticated, "missing or invalid credentials")
+}
+
+// authenticatedStream carries the context returned by authenticate.
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
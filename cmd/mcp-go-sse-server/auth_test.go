@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	mcpserver "mcp-go-sse-server/pkg/server"
+)
+
+func TestAuthenticateAPIKey(t *testing.T) {
+	auth, err := newAuthenticator("s3cretkey, otherkey", "", "", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		authorization string
+		wantOK        bool
+		wantSubject   string
+	}{
+		{"api key", "Bearer s3cretkey", true, apiKeyPrincipal("s3cretkey")},
+		{"second api key", "Bearer otherkey", true, apiKeyPrincipal("otherkey")},
+		{"lowercase scheme", "bearer s3cretkey", true, apiKeyPrincipal("s3cretkey")},
+		{"wrong api key", "Bearer s3cretkez", false, ""},
+		{"prefix of api key", "Bearer s3cret", false, ""},
+		{"empty bearer", "Bearer ", false, ""},
+		{"no credentials", "", false, ""},
+		{"unknown scheme", "Token s3cretkey", false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, ok := auth.authenticate(context.Background(), tt.authorization)
+			if ok != tt.wantOK {
+				t.Fatalf("authenticate() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			claims, _ := mcpserver.ClaimsFromContext(ctx)
+			if claims["sub"] != tt.wantSubject {
+				t.Errorf("sub = %v, want %q", claims["sub"], tt.wantSubject)
+			}
+		})
+	}
+}
+
+func basicHeader(user string, password string) string {
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(user+":"+password))
+}
+
+func TestAuthenticateBasic(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "basic")
+	if err := os.WriteFile(file, []byte("# operators\ncarol:pa:ss\n\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	auth, err := newAuthenticator("", "", "alice:wonderland", file, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		authorization string
+		wantOK        bool
+		wantSubject   string
+	}{
+		{"valid", basicHeader("alice", "wonderland"), true, "alice"},
+		{"from file, colon in password", basicHeader("carol", "pa:ss"), true, "carol"},
+		{"wrong password", basicHeader("alice", "looking-glass"), false, ""},
+		{"unknown user", basicHeader("mallory", "wonderland"), false, ""},
+		{"empty user", basicHeader("", "wonderland"), false, ""},
+		{"comment line is not a credential", basicHeader("# operators", ""), false, ""},
+		{"malformed", "Basic !!!", false, ""},
+		{"password as bearer", "Bearer wonderland", false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, ok := auth.authenticate(context.Background(), tt.authorization)
+			if ok != tt.wantOK {
+				t.Fatalf("authenticate() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			claims, _ := mcpserver.ClaimsFromContext(ctx)
+			if claims["sub"] != tt.wantSubject {
+				t.Errorf("sub = %v, want %q", claims["sub"], tt.wantSubject)
+			}
+		})
+	}
+
+	w := httptest.NewRecorder()
+	auth.middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/sse", nil))
+	if challenge := w.Header().Get("WWW-Authenticate"); !strings.HasPrefix(challenge, "Basic ") {
+		t.Errorf("WWW-Authenticate = %q, want a Basic challenge", challenge)
+	}
+}
+
+func TestAuthenticatorMiddleware(t *testing.T) {
+	auth, err := newAuthenticator("s3cretkey", "", "", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := auth.middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	tests := []struct {
+		name          string
+		target        string
+		authorization string
+		wantStatus    int
+	}{
+		{"bearer", "/mcp", "Bearer s3cretkey", http.StatusOK},
+		{"query parameter", "/mcp?api_key=s3cretkey", "", http.StatusOK},
+		{"wrong query parameter", "/mcp?api_key=nope", "", http.StatusUnauthorized},
+		{"missing", "/mcp", "", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, tt.target, nil)
+			if tt.authorization != "" {
+				r.Header.Set("Authorization", tt.authorization)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if w.Code == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") == "" {
+				t.Error("401 without WWW-Authenticate challenge")
+			}
+		})
+	}
+}
+
+func TestNewAuthenticator(t *testing.T) {
+	if auth, err := newAuthenticator("", "", "", "", nil); auth != nil || err != nil {
+		t.Errorf("newAuthenticator() without credentials = %v, %v, want nil, nil", auth, err)
+	}
+	if auth, err := newAuthenticator(" , ", "", "", "", nil); auth != nil || err != nil {
+		t.Errorf("newAuthenticator() with blank keys = %v, %v, want nil, nil", auth, err)
+	}
+	for _, credentials := range []string{"alice", ":wonderland"} {
+		if _, err := newAuthenticator("", "", credentials, "", nil); err == nil {
+			t.Errorf("newAuthenticator() accepted basic credentials %q", credentials)
+		}
+	}
+}
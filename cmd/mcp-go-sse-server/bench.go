@@ -0,0 +1,300 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	mcpserver "mcp-go-sse-server/pkg/server"
+)
+
+// INPROCESS_TARGET makes the bench subcommand drive an in-process server
+// instead of connecting to a running one.
+const INPROCESS_TARGET = "inprocess"
+
+// benchArguments are the arguments sent to the example tools when the bench
+// mix references them without an explicit --tool-args entry.
+var benchArguments = map[string]map[string]interface{}{
+	string(mcpserver.ECHO):                   {"message": "bench"},
+	string(mcpserver.ADD):                    {"a": 1, "b": 2},
+	string(mcpserver.LONG_RUNNING_OPERATION): {"duration": 0, "steps": 1},
+	string(mcpserver.GET_TINY_IMAGE):         {},
+}
+
+type benchTool struct {
+	name      string
+	weight    int
+	arguments map[string]interface{}
+}
+
+type benchSample struct {
+	tool     string
+	duration time.Duration
+	err      error
+}
+
+// parseBenchMix parses a "tool=weight,tool=weight" list.
+func parseBenchMix(mix string, toolArgs map[string]map[string]interface{}) ([]benchTool, error) {
+	var tools []benchTool
+	for _, entry := range strings.Split(mix, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, weightStr, found := strings.Cut(entry, "=")
+		weight := 1
+		if found {
+			w, err := strconv.Atoi(weightStr)
+			if err != nil || w <= 0 {
+				return nil, fmt.Errorf("invalid weight in mix entry %q", entry)
+			}
+			weight = w
+		}
+		arguments, ok := toolArgs[name]
+		if !ok {
+			arguments = benchArguments[name]
+		}
+		tools = append(tools, benchTool{name: name, weight: weight, arguments: arguments})
+	}
+	if len(tools) == 0 {
+		return nil, fmt.Errorf("empty tool mix")
+	}
+	return tools, nil
+}
+
+func pickBenchTool(tools []benchTool, totalWeight int, rng *rand.Rand) benchTool {
+	n := rng.Intn(totalWeight)
+	for _, tool := range tools {
+		if n < tool.weight {
+			return tool
+		}
+		n -= tool.weight
+	}
+	return tools[len(tools)-1]
+}
+
+// runBench implements the bench subcommand: it opens concurrent SSE sessions
+// against a running server and reports tool call latency and error rates.
+func runBench(args []string) {
+	fs := flag.NewFlagSet("bench", flag.ExitOnError)
+	var target string
+	var sessions int
+	var calls int
+	var mix string
+	var toolArgsJSON string
+	var timeout time.Duration
+	headers := headerFlags{}
+	fs.StringVar(&target, "target", "http://localhost:3001/sse", "SSE endpoint of the server to benchmark, or inprocess for an in-process server.")
+	fs.IntVar(&sessions, "sessions", 10, "Number of concurrent SSE sessions.")
+	fs.IntVar(&calls, "calls", 100, "Number of tool calls per session.")
+	fs.StringVar(&mix, "mix", "echo=1,add=1", "Weighted tool mix as tool=weight pairs.")
+	fs.StringVar(&toolArgsJSON, "tool-args", "", "JSON object mapping tool names to call arguments.")
+	fs.DurationVar(&timeout, "timeout", 30*time.Second, "Timeout for each tool call.")
+	fs.Var(headers, "header", "Request header sent to the server, as key=value, e.g. Authorization=Bearer <key>. Can be repeated.")
+	fs.Parse(args)
+
+	toolArgs := make(map[string]map[string]interface{})
+	if toolArgsJSON != "" {
+		if err := json.Unmarshal([]byte(toolArgsJSON), &toolArgs); err != nil {
+			log.Fatalf("Invalid --tool-args: %v", err)
+		}
+	}
+	tools, err := parseBenchMix(mix, toolArgs)
+	if err != nil {
+		log.Fatalf("Invalid --mix: %v", err)
+	}
+	// Repeated headers are folded into one comma-separated value.
+	requestHeaders := make(map[string]string, len(headers))
+	for key, values := range headers {
+		requestHeaders[key] = strings.Join(values, ", ")
+	}
+	totalWeight := 0
+	for _, tool := range tools {
+		totalWeight += tool.weight
+	}
+
+	// Every in-process session connects to the same server, like SSE
+	// sessions to a running one.
+	var mcpServer *server.MCPServer
+	if target == INPROCESS_TARGET {
+		mcpServer = mcpserver.NewMCPServer(nil)
+	}
+
+	log.Printf("Benchmarking %s with %d sessions x %d calls", target, sessions, calls)
+
+	var mu sync.Mutex
+	var samples []benchSample
+	var sessionErrors []error
+	var wg sync.WaitGroup
+	start := time.Now()
+	for i := 0; i < sessions; i++ {
+		wg.Add(1)
+		go func(seed int64) {
+			defer wg.Done()
+			sessionSamples, err := runBenchSession(target, requestHeaders, mcpServer, tools, totalWeight, calls, timeout, rand.New(rand.NewSource(seed)))
+			mu.Lock()
+			defer mu.Unlock()
+			samples = append(samples, sessionSamples...)
+			if err != nil {
+				sessionErrors = append(sessionErrors, err)
+			}
+		}(time.Now().UnixNano() + int64(i))
+	}
+	wg.Wait()
+	elapsed := time.Since(start)
+
+	printBenchReport(os.Stdout, samples, sessionErrors, sessions, elapsed)
+	if len(sessionErrors) > 0 || len(samples) == 0 {
+		os.Exit(1)
+	}
+}
+
+func runBenchSession(
+	target string,
+	headers map[string]string,
+	mcpServer *server.MCPServer,
+	tools []benchTool,
+	totalWeight int,
+	calls int,
+	timeout time.Duration,
+	rng *rand.Rand,
+) ([]benchSample, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	c, err := newBenchClient(ctx, target, headers, mcpServer)
+	cancel()
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	samples := make([]benchSample, 0, calls)
+	for i := 0; i < calls; i++ {
+		tool := pickBenchTool(tools, totalWeight, rng)
+		request := mcp.CallToolRequest{}
+		request.Params.Name = tool.name
+		request.Params.Arguments = tool.arguments
+
+		callCtx, callCancel := context.WithTimeout(context.Background(), timeout)
+		callStart := time.Now()
+		result, err := c.CallTool(callCtx, request)
+		callDuration := time.Since(callStart)
+		callCancel()
+		if err == nil && result.IsError {
+			err = fmt.Errorf("tool returned an error result")
+		}
+		samples = append(samples, benchSample{tool: tool.name, duration: callDuration, err: err})
+	}
+	return samples, nil
+}
+
+// newBenchClient returns an initialized client for target, sending headers
+// with every request, or an in-process client of mcpServer for the inprocess
+// target. ctx bounds connecting and initializing only; the stream stays open
+// until the client is closed.
+func newBenchClient(ctx context.Context, target string, headers map[string]string, mcpServer *server.MCPServer) (*client.Client, error) {
+	if target == INPROCESS_TARGET {
+		return mcpserver.NewInProcessClient(ctx, mcpServer)
+	}
+	c, err := client.NewSSEMCPClient(target, client.WithHeaders(headers))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client: %w", err)
+	}
+	if err := startClient(ctx, c); err != nil {
+		c.Close()
+		return nil, fmt.Errorf("failed to connect: %w", err)
+	}
+	initRequest := mcp.InitializeRequest{}
+	initRequest.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	initRequest.Params.ClientInfo = mcp.Implementation{
+		Name:    "mcp-go-sse-server-bench",
+		Version: "1.0.0",
+	}
+	if _, err := c.Initialize(ctx, initRequest); err != nil {
+		c.Close()
+		return nil, fmt.Errorf("failed to initialize: %w", err)
+	}
+	return c, nil
+}
+
+// startClient starts c with a context that lives as long as the client, since
+// the SSE transport closes its stream when the start context ends. The stream
+// is only cut if ctx ends before it is up.
+func startClient(ctx context.Context, c *client.Client) error {
+	streamCtx, stopStream := context.WithCancel(context.WithoutCancel(ctx))
+	stop := context.AfterFunc(ctx, stopStream)
+	err := c.Start(streamCtx)
+	if !stop() && err == nil {
+		err = ctx.Err()
+	}
+	return err
+}
+
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	idx := int(float64(len(sorted)-1) * p)
+	return sorted[idx]
+}
+
+func printBenchReport(
+	w io.Writer,
+	samples []benchSample,
+	sessionErrors []error,
+	sessions int,
+	elapsed time.Duration,
+) {
+	byTool := make(map[string][]benchSample)
+	for _, sample := range samples {
+		byTool[sample.tool] = append(byTool[sample.tool], sample)
+	}
+	names := make([]string, 0, len(byTool))
+	for name := range byTool {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	byTool["total"] = samples
+	names = append(names, "total")
+
+	fmt.Fprintf(w, "\nSessions: %d ok, %d failed\n", sessions-len(sessionErrors), len(sessionErrors))
+	for _, err := range sessionErrors {
+		fmt.Fprintf(w, "  %v\n", err)
+	}
+	fmt.Fprintf(w, "Elapsed: %s, throughput: %.1f calls/s\n\n", elapsed.Round(time.Millisecond), float64(len(samples))/elapsed.Seconds())
+	fmt.Fprintf(w, "%-24s %8s %8s %10s %10s %10s %10s\n", "tool", "calls", "errors", "p50", "p90", "p99", "max")
+	for _, name := range names {
+		toolSamples := byTool[name]
+		durations := make([]time.Duration, 0, len(toolSamples))
+		errors := 0
+		for _, sample := range toolSamples {
+			if sample.err != nil {
+				errors++
+			}
+			durations = append(durations, sample.duration)
+		}
+		sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+		fmt.Fprintf(w, "%-24s %8d %7.1f%% %10s %10s %10s %10s\n",
+			name,
+			len(toolSamples),
+			100*float64(errors)/float64(max(len(toolSamples), 1)),
+			percentile(durations, 0.50).Round(time.Microsecond),
+			percentile(durations, 0.90).Round(time.Microsecond),
+			percentile(durations, 0.99).Round(time.Microsecond),
+			percentile(durations, 1).Round(time.Microsecond),
+		)
+	}
+}
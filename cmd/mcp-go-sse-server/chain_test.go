@@ -0,0 +1,63 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// tracingRegistry returns middlewares that record their names in the
+// X-Trace response header in the order requests pass through them.
+func tracingRegistry(names ...string) middlewareRegistry {
+	m := middlewareRegistry{}
+	for _, name := range names {
+		m[name] = func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Add("X-Trace", name)
+				next.ServeHTTP(w, r)
+			})
+		}
+	}
+	return m
+}
+
+func TestBuildChain(t *testing.T) {
+	m := tracingRegistry("access-log", "gzip", "max-bytes", "origin")
+	tests := []struct {
+		name      string
+		order     string
+		defaults  []string
+		required  []string
+		wantTrace string
+		wantErr   bool
+	}{
+		{"empty", "", nil, nil, "", false},
+		{"explicit order", "gzip, access-log", nil, nil, "gzip access-log", false},
+		{"default", DEFAULT_MIDDLEWARE, []string{"access-log", "gzip"}, nil, "access-log gzip", false},
+		{"around default", "origin,default,max-bytes", []string{"access-log", "gzip"}, nil, "origin access-log gzip max-bytes", false},
+		{"required added outermost", "gzip", nil, []string{"max-bytes", "origin"}, "max-bytes origin gzip", false},
+		{"required already listed", "gzip,origin", nil, []string{"origin"}, "gzip origin", false},
+		{"required in default", DEFAULT_MIDDLEWARE, []string{"gzip", "origin"}, []string{"origin"}, "gzip origin", false},
+		{"unknown", "gzip,compress", nil, nil, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, err := m.buildChain(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}), tt.order, tt.defaults, tt.required)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("buildChain() accepted an unknown middleware")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+			if got := strings.Join(w.Header().Values("X-Trace"), " "); got != tt.wantTrace {
+				t.Errorf("trace = %q, want %q", got, tt.wantTrace)
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"sync"
+)
+
+// ENCRYPTED_RECORD_PREFIX starts every line written by an encryptingWriter,
+// followed by the key ID and the base64 nonce and ciphertext.
+const ENCRYPTED_RECORD_PREFIX = "enc1"
+
+// keyring holds the AES-256-GCM keys data at rest is encrypted with. New
+// records use the active key; the others only decrypt records written before
+// a rotation.
+type keyring struct {
+	active string
+	keys   map[string]cipher.AEAD
+}
+
+// parseKeyring parses a comma-separated list of id=base64key pairs, where each
+// key is 32 random bytes, e.g. from `openssl rand -base64 32`. The first key
+// is the active one. To rotate, prepend a new key and keep the old ones as
+// long as records written with them must stay readable.
+func parseKeyring(list string) (*keyring, error) {
+	ring := &keyring{keys: map[string]cipher.AEAD{}}
+	for _, entry := range strings.Split(list, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		id, encoded, ok := strings.Cut(entry, "=")
+		if !ok || id == "" || strings.Contains(id, ":") {
+			return nil, fmt.Errorf("encryption key %q is not id=base64key", id)
+		}
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(key) != 32 {
+			return nil, fmt.Errorf("encryption key %q must be 32 bytes in base64", id)
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := ring.keys[id]; ok {
+			return nil, fmt.Errorf("duplicate encryption key %q", id)
+		}
+		ring.keys[id] = aead
+		if ring.active == "" {
+			ring.active = id
+		}
+	}
+	if ring.active == "" {
+		return nil, errors.New("no encryption key given")
+	}
+	return ring, nil
+}
+
+// seal encrypts plaintext into one record line, without the newline.
+func (k *keyring) seal(plaintext []byte) (string, error) {
+	aead := k.keys[k.active]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, plaintext, []byte(k.active))
+	return ENCRYPTED_RECORD_PREFIX + ":" + k.active + ":" + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// open decrypts a record line written by seal.
+func (k *keyring) open(record string) ([]byte, error) {
+	parts := strings.SplitN(record, ":", 3)
+	if len(parts) != 3 || parts[0] != ENCRYPTED_RECORD_PREFIX {
+		return nil, errors.New("not an encrypted record")
+	}
+	aead, ok := k.keys[parts[1]]
+	if !ok {
+		return nil, fmt.Errorf("unknown encryption key %q", parts[1])
+	}
+	sealed, err := base64.StdEncoding.DecodeString(parts[2])
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return nil, errors.New("malformed encrypted record")
+	}
+	return aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], []byte(parts[1]))
+}
+
+// encryptingWriter encrypts each Write as one record line, so a writer that
+// emits one record per Write, like a slog handler, stays line-oriented on
+// disk.
+type encryptingWriter struct {
+	mu   sync.Mutex
+	w    io.Writer
+	ring *keyring
+}
+
+func (e *encryptingWriter) Write(p []byte) (int, error) {
+	record, err := e.ring.seal(p)
+	if err != nil {
+		return 0, err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if _, err := io.WriteString(e.w, record+"\n"); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// runDecrypt implements the decrypt subcommand, which prints the plaintext of
+// encrypted records read from stdin.
+func runDecrypt(args []string) {
+	fs := flag.NewFlagSet("decrypt", flag.ExitOnError)
+	var keys string
+	fs.StringVar(&keys, "encryption-keys", "", "Keys the records were written with, as id=base64key pairs.")
+	fs.Parse(args)
+	if err := applyEnv(fs); err != nil {
+		log.Fatalf("Config error: %v", err)
+	}
+	ring, err := parseKeyring(keys)
+	if err != nil {
+		log.Fatalf("Config error: %v", err)
+	}
+	scanner := bufio.NewScanner(os.Stdin)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	out := bufio.NewWriter(os.Stdout)
+	defer out.Flush()
+	for line := 1; scanner.Scan(); line++ {
+		plaintext, err := ring.open(scanner.Text())
+		if err != nil {
+			out.Flush()
+			log.Fatalf("Line %d: %v", line, err)
+		}
+		out.Write(plaintext)
+	}
+	if err := scanner.Err(); err != nil {
+		log.Fatalf("Failed to read input: %v", err)
+	}
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func newTestKeyEntry(t *testing.T, id string) string {
+	t.Helper()
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatal(err)
+	}
+	return id + "=" + base64.StdEncoding.EncodeToString(key)
+}
+
+func mustParseKeyring(t *testing.T, list string) *keyring {
+	t.Helper()
+	ring, err := parseKeyring(list)
+	if err != nil {
+		t.Fatalf("parseKeyring(%q): %v", list, err)
+	}
+	return ring
+}
+
+func TestKeyringRoundTrip(t *testing.T) {
+	ring := mustParseKeyring(t, newTestKeyEntry(t, "k1"))
+	plaintext := []byte(`{"msg":"tool call","tool":"echo"}`)
+	record, err := ring.seal(plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(record, ENCRYPTED_RECORD_PREFIX+":k1:") {
+		t.Errorf("record %q does not name its key", record)
+	}
+	if strings.Contains(record, "echo") {
+		t.Error("record contains the plaintext")
+	}
+	opened, err := ring.open(record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(opened, plaintext) {
+		t.Errorf("open() = %q, want %q", opened, plaintext)
+	}
+
+	again, err := ring.seal(plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again == record {
+		t.Error("sealing twice gave the same record, nonces are reused")
+	}
+}
+
+func TestKeyringRotation(t *testing.T) {
+	oldKey := newTestKeyEntry(t, "k1")
+	newKey := newTestKeyEntry(t, "k2")
+	oldRing := mustParseKeyring(t, oldKey)
+	rotated := mustParseKeyring(t, newKey+","+oldKey)
+	newOnly := mustParseKeyring(t, newKey)
+
+	oldRecord, err := oldRing.seal([]byte("before rotation"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opened, err := rotated.open(oldRecord); err != nil || string(opened) != "before rotation" {
+		t.Errorf("rotated ring open(old record) = %q, %v", opened, err)
+	}
+	newRecord, err := rotated.seal([]byte("after rotation"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(newRecord, ENCRYPTED_RECORD_PREFIX+":k2:") {
+		t.Errorf("rotated ring sealed with the old key: %q", newRecord)
+	}
+	if _, err := newOnly.open(oldRecord); err == nil {
+		t.Error("ring without k1 opened a record sealed with k1")
+	}
+	if _, err := oldRing.open(newRecord); err == nil {
+		t.Error("ring without k2 opened a record sealed with k2")
+	}
+}
+
+func TestKeyringOpenRejectsTampering(t *testing.T) {
+	ring := mustParseKeyring(t, newTestKeyEntry(t, "k1")+","+newTestKeyEntry(t, "k2"))
+	record, err := ring.seal([]byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := strings.SplitN(record, ":", 3)
+	sealed, _ := base64.StdEncoding.DecodeString(parts[2])
+	sealed[len(sealed)-1] ^= 1
+
+	tests := []struct {
+		name   string
+		record string
+	}{
+		{"flipped bit", parts[0] + ":" + parts[1] + ":" + base64.StdEncoding.EncodeToString(sealed)},
+		{"other key ID", parts[0] + ":k2:" + parts[2]},
+		{"unknown key ID", parts[0] + ":k3:" + parts[2]},
+		{"truncated", parts[0] + ":" + parts[1] + ":" + parts[2][:8]},
+		{"not base64", parts[0] + ":" + parts[1] + ":!!!"},
+		{"plaintext", `{"msg":"tool call"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ring.open(tt.record); err == nil {
+				t.Errorf("open(%q) succeeded", tt.record)
+			}
+		})
+	}
+}
+
+func TestParseKeyringErrors(t *testing.T) {
+	key := newTestKeyEntry(t, "k1")
+	tests := []struct {
+		name string
+		list string
+	}{
+		{"empty", ""},
+		{"missing id", "=" + strings.TrimPrefix(key, "k1=")},
+		{"colon in id", "k:1=" + strings.TrimPrefix(key, "k1=")},
+		{"short key", "k1=" + base64.StdEncoding.EncodeToString(make([]byte, 16))},
+		{"not base64", "k1=???"},
+		{"duplicate id", key + "," + key},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parseKeyring(tt.list); err == nil {
+				t.Errorf("parseKeyring(%q) succeeded", tt.list)
+			}
+		})
+	}
+}
+
+func TestEncryptingWriter(t *testing.T) {
+	ring := mustParseKeyring(t, newTestKeyEntry(t, "k1"))
+	var out bytes.Buffer
+	w := &encryptingWriter{w: &out, ring: ring}
+	for _, line := range []string{"first\n", "second\n"} {
+		if n, err := w.Write([]byte(line)); err != nil || n != len(line) {
+			t.Fatalf("Write(%q) = %d, %v", line, n, err)
+		}
+	}
+	records := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if len(records) != 2 {
+		t.Fatalf("got %d records, want 2", len(records))
+	}
+	for i, want := range []string{"first\n", "second\n"} {
+		if opened, err := ring.open(records[i]); err != nil || string(opened) != want {
+			t.Errorf("record %d opened to %q, %v, want %q", i, opened, err, want)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ENV_PREFIX starts the environment variable of every flag, e.g.
+// MCP_ADMIN_TOKEN for --admin-token.
+const ENV_PREFIX = "MCP_"
+
+// envName returns the environment variable read for the flag name.
+func envName(name string) string {
+	return ENV_PREFIX + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+// applyEnv sets every flag not given on the command line from its
+// environment variable, or from the file named by the same variable with a
+// _FILE suffix, so secrets stay out of process listings. Where a flag has a
+// -file companion, such as --api-keys-file, the _FILE variable belongs to the
+// companion. Command-line flags take precedence over the environment, which
+// takes precedence over defaults.
+func applyEnv(fs *flag.FlagSet) error {
+	explicit := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) {
+		explicit[f.Name] = true
+	})
+	var err error
+	fs.VisitAll(func(f *flag.Flag) {
+		if err != nil || explicit[f.Name] {
+			return
+		}
+		name := envName(f.Name)
+		value, ok := os.LookupEnv(name)
+		path, fromFile := os.LookupEnv(name + "_FILE")
+		if fromFile && fs.Lookup(f.Name+"-file") == nil {
+			if ok {
+				err = fmt.Errorf("%s and %s_FILE cannot both be set", name, name)
+				return
+			}
+			data, readErr := os.ReadFile(path)
+			if readErr != nil {
+				err = fmt.Errorf("%s_FILE: %w", name, readErr)
+				return
+			}
+			value, ok = strings.TrimRight(string(data), "\r\n"), true
+		}
+		if !ok {
+			return
+		}
+		if setErr := fs.Set(f.Name, value); setErr != nil {
+			err = fmt.Errorf("%s: %w", name, setErr)
+		}
+	})
+	return err
+}
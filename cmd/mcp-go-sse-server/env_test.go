@@ -0,0 +1,72 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEnvName(t *testing.T) {
+	if got := envName("admin-token"); got != "MCP_ADMIN_TOKEN" {
+		t.Errorf("envName(admin-token) = %q, want MCP_ADMIN_TOKEN", got)
+	}
+}
+
+func TestApplyEnv(t *testing.T) {
+	dir := t.TempDir()
+	secret := filepath.Join(dir, "secret")
+	if err := os.WriteFile(secret, []byte("from-file\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		env     map[string]string
+		want    map[string]string
+		wantErr bool
+	}{
+		{"defaults", nil, nil, map[string]string{"port": "3000", "admin-token": "", "verbose": "false"}, false},
+		{"environment over default", nil, map[string]string{"MCP_PORT": "4000", "MCP_VERBOSE": "true"}, map[string]string{"port": "4000", "verbose": "true"}, false},
+		{"command line over environment", []string{"--port", "5000"}, map[string]string{"MCP_PORT": "4000"}, map[string]string{"port": "5000"}, false},
+		{"command line empty value over environment", []string{"--admin-token="}, map[string]string{"MCP_ADMIN_TOKEN": "env-token"}, map[string]string{"admin-token": ""}, false},
+		{"value from file", nil, map[string]string{"MCP_ADMIN_TOKEN_FILE": secret}, map[string]string{"admin-token": "from-file"}, false},
+		{"command line over file", []string{"--admin-token", "flag-token"}, map[string]string{"MCP_ADMIN_TOKEN_FILE": secret}, map[string]string{"admin-token": "flag-token"}, false},
+		{"_FILE of a flag with a -file companion", nil, map[string]string{"MCP_API_KEYS_FILE": secret}, map[string]string{"api-keys": "", "api-keys-file": secret}, false},
+		{"value and file both set", nil, map[string]string{"MCP_ADMIN_TOKEN": "env-token", "MCP_ADMIN_TOKEN_FILE": secret}, nil, true},
+		{"missing file", nil, map[string]string{"MCP_ADMIN_TOKEN_FILE": filepath.Join(dir, "missing")}, nil, true},
+		{"invalid value", nil, map[string]string{"MCP_PORT": "many"}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			fs.Int("port", 3000, "")
+			fs.String("admin-token", "", "")
+			fs.Bool("verbose", false, "")
+			fs.String("api-keys", "", "")
+			fs.String("api-keys-file", "", "")
+			if err := fs.Parse(tt.args); err != nil {
+				t.Fatal(err)
+			}
+			err := applyEnv(fs)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("applyEnv() succeeded, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			for name, want := range tt.want {
+				if got := fs.Lookup(name).Value.String(); got != want {
+					t.Errorf("--%s = %q, want %q", name, got, want)
+				}
+			}
+		})
+	}
+}
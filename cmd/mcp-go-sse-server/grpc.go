@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"sync"
+	"sync/atomic"
+
+	"github.com/google/uuid"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// grpcServiceDesc describes the mcp.v1.MCP service from proto/mcp.proto. The
+// messages are google.protobuf.BytesValue, so no generated code is needed.
+var grpcServiceDesc = grpc.ServiceDesc{
+	ServiceName: "mcp.v1.MCP",
+	HandlerType: (*any)(nil),
+	Streams: []grpc.StreamDesc{{
+		StreamName:    "Session",
+		Handler:       grpcSessionHandler,
+		ServerStreams: true,
+		ClientStreams: true,
+	}},
+	Metadata: "proto/mcp.proto",
+}
+
+// grpcTransport serves MCP sessions over gRPC streams.
+type grpcTransport struct {
+	mcpServer *server.MCPServer
+}
+
+// newGRPCServer returns a gRPC server exposing mcpServer as the mcp.v1.MCP
+// service, guarded by auth when it is not nil, counting its streams in
+// sessions, and serving TLS when tlsConfig is not nil.
+func newGRPCServer(mcpServer *server.MCPServer, auth *authenticator, sessions *sessionTracker, tlsConfig *tls.Config) *grpc.Server {
+	var interceptors []grpc.StreamServerInterceptor
+	if auth != nil {
+		interceptors = append(interceptors, auth.streamInterceptor)
+	}
+	interceptors = append(interceptors, sessions.streamInterceptor)
+	opts := []grpc.ServerOption{grpc.ChainStreamInterceptor(interceptors...)}
+	if tlsConfig != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+	grpcServer := grpc.NewServer(opts...)
+	grpcServer.RegisterService(&grpcServiceDesc, &grpcTransport{mcpServer: mcpServer})
+	return grpcServer
+}
+
+func grpcSessionHandler(srv any, stream grpc.ServerStream) error {
+	return srv.(*grpcTransport).serveSession(stream)
+}
+
+// grpcSession is the MCP session of one gRPC stream.
+type grpcSession struct {
+	id            string
+	notifications chan mcp.JSONRPCNotification
+	initialized   atomic.Bool
+	clientInfo    atomic.Value
+	capabilities  atomic.Value
+}
+
+func (s *grpcSession) SessionID() string { return s.id }
+
+func (s *grpcSession) NotificationChannel() chan<- mcp.JSONRPCNotification {
+	return s.notifications
+}
+
+func (s *grpcSession) Initialize() { s.initialized.Store(true) }
+
+func (s *grpcSession) Initialized() bool { return s.initialized.Load() }
+
+func (s *grpcSession) GetClientInfo() mcp.Implementation {
+	if info, ok := s.clientInfo.Load().(mcp.Implementation); ok {
+		return info
+	}
+	return mcp.Implementation{}
+}
+
+func (s *grpcSession) SetClientInfo(info mcp.Implementation) { s.clientInfo.Store(info) }
+
+func (s *grpcSession) GetClientCapabilities() mcp.ClientCapabilities {
+	if capabilities, ok := s.capabilities.Load().(mcp.ClientCapabilities); ok {
+		return capabilities
+	}
+	return mcp.ClientCapabilities{}
+}
+
+func (s *grpcSession) SetClientCapabilities(capabilities mcp.ClientCapabilities) {
+	s.capabilities.Store(capabilities)
+}
+
+// serveSession registers a session for the stream and writes responses and
+// notifications back on it. Like the stdio transport, tool calls run
+// concurrently while every other message is handled in order.
+func (t *grpcTransport) serveSession(stream grpc.ServerStream) error {
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+
+	session := &grpcSession{
+		id:            uuid.New().String(),
+		notifications: make(chan mcp.JSONRPCNotification, 100),
+	}
+	if err := t.mcpServer.RegisterSession(ctx, session); err != nil {
+		return err
+	}
+	defer t.mcpServer.UnregisterSession(ctx, session.id)
+	ctx = t.mcpServer.WithContext(ctx, session)
+
+	// grpc.ServerStream.SendMsg is not safe for concurrent use.
+	var sendMu sync.Mutex
+	send := func(message any) {
+		data, err := json.Marshal(message)
+		if err != nil {
+			log.Printf("gRPC session %s: failed to marshal message: %v", session.id, err)
+			return
+		}
+		sendMu.Lock()
+		defer sendMu.Unlock()
+		if err := stream.SendMsg(wrapperspb.Bytes(data)); err != nil {
+			cancel()
+		}
+	}
+
+	go func() {
+		for {
+			select {
+			case notification := <-session.notifications:
+				send(notification)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var handlers sync.WaitGroup
+	defer handlers.Wait()
+	for {
+		in := &wrapperspb.BytesValue{}
+		if err := stream.RecvMsg(in); err != nil {
+			if errors.Is(err, io.EOF) || ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		message := json.RawMessage(in.GetValue())
+		var base struct {
+			Method string `json:"method"`
+		}
+		if json.Unmarshal(message, &base) == nil && base.Method == string(mcp.MethodToolsCall) {
+			handlers.Add(1)
+			go func() {
+				defer handlers.Done()
+				if response := t.mcpServer.HandleMessage(ctx, message); response != nil {
+					send(response)
+				}
+			}()
+			continue
+		}
+		if response := t.mcpServer.HandleMessage(ctx, message); response != nil {
+			send(response)
+		}
+	}
+}
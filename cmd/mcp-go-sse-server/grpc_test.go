@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	mcpserver "mcp-go-sse-server/pkg/server"
+)
+
+const grpcTestInitialize = `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","clientInfo":{"name":"test","version":"1.0.0"},"capabilities":{}}}`
+
+// newTestCertificate returns a self-signed certificate for 127.0.0.1 and a
+// pool trusting it.
+func newTestCertificate(t *testing.T) (tls.Certificate, *x509.CertPool) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(leaf)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}, pool
+}
+
+// startTestGRPCServer serves a gRPC transport on a local port and returns its
+// address.
+func startTestGRPCServer(t *testing.T, sessions *sessionTracker, tlsConfig *tls.Config) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	grpcServer := newGRPCServer(mcpserver.NewMCPServer(nil), nil, sessions, tlsConfig)
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+	return listener.Addr().String()
+}
+
+// initializeGRPCSession opens a session stream on conn and sends initialize,
+// returning the stream and the error of reading the response.
+func initializeGRPCSession(ctx context.Context, t *testing.T, conn *grpc.ClientConn) (grpc.ClientStream, error) {
+	t.Helper()
+	stream, err := conn.NewStream(ctx, &grpcServiceDesc.Streams[0], "/mcp.v1.MCP/Session")
+	if err != nil {
+		return nil, err
+	}
+	if err := stream.SendMsg(wrapperspb.Bytes([]byte(grpcTestInitialize))); err != nil {
+		return nil, err
+	}
+	return stream, stream.RecvMsg(&wrapperspb.BytesValue{})
+}
+
+func TestGRPCTransportTLS(t *testing.T) {
+	cert, pool := newTestCertificate(t)
+	sessions := &sessionTracker{registry: newSessionRegistry()}
+	addr := startTestGRPCServer(t, sessions, &tls.Config{Certificates: []tls.Certificate{cert}})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{RootCAs: pool})))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if _, err := initializeGRPCSession(ctx, t, conn); err != nil {
+		t.Fatalf("session over TLS: %v", err)
+	}
+
+	plaintext, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer plaintext.Close()
+	if _, err := initializeGRPCSession(ctx, t, plaintext); status.Code(err) != codes.Unavailable {
+		t.Errorf("plaintext session error = %v, want Unavailable", err)
+	}
+}
+
+func TestGRPCSessionAccounting(t *testing.T) {
+	sessions := &sessionTracker{registry: newSessionRegistry(), max: 1}
+	addr := startTestGRPCServer(t, sessions, nil)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	first, err := initializeGRPCSession(ctx, t, conn)
+	if err != nil {
+		t.Fatalf("first session: %v", err)
+	}
+	if active := sessions.active.Load(); active != 1 {
+		t.Errorf("active = %d, want 1", active)
+	}
+	if _, err := initializeGRPCSession(ctx, t, conn); status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("session over the limit error = %v, want ResourceExhausted", err)
+	}
+
+	first.CloseSend()
+	first.RecvMsg(&wrapperspb.BytesValue{})
+	for sessions.active.Load() != 0 {
+		if ctx.Err() != nil {
+			t.Fatal("closed session is still counted")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	sessions.drain()
+	if _, err := initializeGRPCSession(ctx, t, conn); status.Code(err) != codes.Unavailable {
+		t.Errorf("session while draining error = %v, want Unavailable", err)
+	}
+	if rejected := sessions.rejected.Load(); rejected != 2 {
+		t.Errorf("rejected = %d, want 2", rejected)
+	}
+}
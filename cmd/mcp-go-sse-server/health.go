@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"runtime"
+	"time"
+)
+
+var startTime = time.Now()
+
+// handleHealthz is the liveness probe. It only reports on the process
+// itself and does not depend on MCP session state.
+func handleHealthz(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":     "ok",
+		"pid":        os.Getpid(),
+		"uptime":     time.Since(startTime).Round(time.Second).String(),
+		"goroutines": runtime.NumGoroutine(),
+		"go_version": runtime.Version(),
+	})
+}
+
+// readinessCheck reports whether a dependency can serve traffic.
+type readinessCheck func(ctx context.Context) error
+
+// handleReadyz is the readiness probe. It runs every check and answers 503
+// if any of them fails.
+func handleReadyz(checks map[string]readinessCheck) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		defer cancel()
+
+		status := http.StatusOK
+		results := make(map[string]string, len(checks))
+		for name, check := range checks {
+			if err := check(ctx); err != nil {
+				status = http.StatusServiceUnavailable
+				results[name] = err.Error()
+			} else {
+				results[name] = "ok"
+			}
+		}
+		ready := "ready"
+		if status != http.StatusOK {
+			ready = "not ready"
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status": ready,
+			"checks": results,
+		})
+	}
+}
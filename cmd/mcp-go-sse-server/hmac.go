@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Headers of a signed request. The signature is "sha256=" + hex(HMAC-SHA256)
+// of the lines timestamp, nonce, method, path, raw query and Mcp-Session-Id
+// header, followed by the body, so a signed request cannot be replayed
+// against another endpoint or session.
+const (
+	SIGNATURE_HEADER           = "X-Mcp-Signature"
+	SIGNATURE_TIMESTAMP_HEADER = "X-Mcp-Timestamp"
+	SIGNATURE_NONCE_HEADER     = "X-Mcp-Nonce"
+)
+
+// SIGNATURE_MAX_SKEW is how far a request timestamp may be from the server
+// clock. Nonces are remembered for twice as long, so a captured request
+// cannot be replayed.
+const SIGNATURE_MAX_SKEW = 5 * time.Minute
+
+// Bounds on the nonces a signer remembers. Only correctly signed requests
+// record a nonce, but a client holding the secret could still flood the
+// server with them.
+const (
+	SIGNATURE_MAX_NONCE_LENGTH = 128
+	SIGNATURE_MAX_NONCES       = 100000
+)
+
+type seenNonce struct {
+	nonce   string
+	expires time.Time
+}
+
+// requestSigner verifies HMAC signatures over requests.
+type requestSigner struct {
+	secret []byte
+
+	mu     sync.Mutex
+	nonces map[string]bool
+	// expiry holds the nonces in the order they expire, which is the order
+	// they were seen.
+	expiry []seenNonce
+}
+
+func newRequestSigner(secret string) *requestSigner {
+	return &requestSigner{secret: []byte(secret), nonces: make(map[string]bool)}
+}
+
+// sign returns the signature header value for r with the given body. The
+// path is the escaped path the server receives, including --base-path.
+func (s *requestSigner) sign(r *http.Request, timestamp string, nonce string, body []byte) string {
+	mac := hmac.New(sha256.New, s.secret)
+	for _, line := range []string{timestamp, nonce, r.Method, r.URL.EscapedPath(), r.URL.RawQuery, r.Header.Get(server.HeaderKeySessionID)} {
+		mac.Write([]byte(line + "\n"))
+	}
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// useNonce records nonce and returns the reason it is rejected, or "" when
+// it had not been seen before. Expired nonces are dropped from the front of
+// the expiry queue.
+func (s *requestSigner) useNonce(nonce string, now time.Time) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for len(s.expiry) > 0 && now.After(s.expiry[0].expires) {
+		delete(s.nonces, s.expiry[0].nonce)
+		s.expiry = s.expiry[1:]
+	}
+	if s.nonces[nonce] {
+		return "nonce already used"
+	}
+	if len(s.nonces) >= SIGNATURE_MAX_NONCES {
+		return "too many signed requests, try again later"
+	}
+	s.nonces[nonce] = true
+	s.expiry = append(s.expiry, seenNonce{nonce: nonce, expires: now.Add(2 * SIGNATURE_MAX_SKEW)})
+	return ""
+}
+
+// verify checks the signature, timestamp and nonce of r and returns the
+// reason it is rejected, or "" when it is valid. The body is read and
+// replaced so handlers can still read it.
+func (s *requestSigner) verify(r *http.Request) string {
+	signature := r.Header.Get(SIGNATURE_HEADER)
+	timestamp := r.Header.Get(SIGNATURE_TIMESTAMP_HEADER)
+	nonce := r.Header.Get(SIGNATURE_NONCE_HEADER)
+	if signature == "" || timestamp == "" || nonce == "" {
+		return "missing signature headers"
+	}
+	if len(nonce) > SIGNATURE_MAX_NONCE_LENGTH {
+		return "nonce too long"
+	}
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return "invalid timestamp"
+	}
+	now := time.Now()
+	if skew := now.Sub(time.Unix(seconds, 0)).Abs(); skew > SIGNATURE_MAX_SKEW {
+		return "timestamp outside the allowed window"
+	}
+	body, err := io.ReadAll(r.Body)
+	r.Body.Close()
+	if err != nil {
+		return "failed to read body"
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	expected := s.sign(r, timestamp, nonce, body)
+	if !hmac.Equal([]byte(strings.ToLower(signature)), []byte(expected)) {
+		return "invalid signature"
+	}
+	return s.useNonce(nonce, now)
+}
+
+// middleware rejects requests without a valid signature with 401. Requests
+// without a body, such as the streamable HTTP GET and DELETE, are signed over
+// an empty body, so a captured session ID cannot be used to open or delete
+// its session. A nil signer lets everything through.
+func (s *requestSigner) middleware(next http.Handler) http.Handler {
+	if s == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if reason := s.verify(r); reason != "" {
+			http.Error(w, "Unauthorized: "+reason, http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func newSignedRequest(signer *requestSigner, method string, body string, timestamp time.Time, nonce string) *http.Request {
+	return newSignedRequestTo(signer, method, "/mcp", nil, body, timestamp, nonce)
+}
+
+func newSignedRequestTo(signer *requestSigner, method string, target string, header http.Header, body string, timestamp time.Time, nonce string) *http.Request {
+	r := httptest.NewRequest(method, target, strings.NewReader(body))
+	for key, values := range header {
+		r.Header[key] = values
+	}
+	ts := strconv.FormatInt(timestamp.Unix(), 10)
+	r.Header.Set(SIGNATURE_TIMESTAMP_HEADER, ts)
+	r.Header.Set(SIGNATURE_NONCE_HEADER, nonce)
+	r.Header.Set(SIGNATURE_HEADER, signer.sign(r, ts, nonce, []byte(body)))
+	return r
+}
+
+// retarget returns a copy of r with its signature headers sent to another
+// method, target or session.
+func retarget(r *http.Request, method string, target string, header http.Header, body string) *http.Request {
+	moved := httptest.NewRequest(method, target, strings.NewReader(body))
+	for _, key := range []string{SIGNATURE_HEADER, SIGNATURE_TIMESTAMP_HEADER, SIGNATURE_NONCE_HEADER} {
+		moved.Header.Set(key, r.Header.Get(key))
+	}
+	for key, values := range header {
+		moved.Header[key] = values
+	}
+	return moved
+}
+
+func TestRequestSignerVerify(t *testing.T) {
+	signer := newRequestSigner("shared-secret")
+	other := newRequestSigner("other-secret")
+	now := time.Now()
+	body := `{"jsonrpc":"2.0","id":1,"method":"tools/list"}`
+
+	tampered := newSignedRequest(signer, http.MethodPost, body, now, "tampered")
+	tampered.Body = httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(body+" ")).Body
+	unsigned := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(body))
+	message := newSignedRequestTo(signer, http.MethodPost, "/message?sessionId=abc", nil, body, now, "message")
+	streamable := newSignedRequestTo(signer, http.MethodPost, "/mcp", http.Header{server.HeaderKeySessionID: {"abc"}}, body, now, "streamable")
+	deletion := newSignedRequestTo(signer, http.MethodDelete, "/mcp", http.Header{server.HeaderKeySessionID: {"abc"}}, "", now, "delete")
+
+	tests := []struct {
+		name       string
+		request    *http.Request
+		wantReason string
+	}{
+		{"valid", newSignedRequest(signer, http.MethodPost, body, now, "n1"), ""},
+		{"replayed nonce", newSignedRequest(signer, http.MethodPost, body, now, "n1"), "nonce already used"},
+		{"delete over empty body", newSignedRequest(signer, http.MethodDelete, "", now, "n2"), ""},
+		{"wrong secret", newSignedRequest(other, http.MethodPost, body, now, "n3"), "invalid signature"},
+		{"tampered body", tampered, "invalid signature"},
+		{"stale timestamp", newSignedRequest(signer, http.MethodPost, body, now.Add(-2*SIGNATURE_MAX_SKEW), "n4"), "timestamp outside the allowed window"},
+		{"future timestamp", newSignedRequest(signer, http.MethodPost, body, now.Add(2*SIGNATURE_MAX_SKEW), "n5"), "timestamp outside the allowed window"},
+		{"nonce too long", newSignedRequest(signer, http.MethodPost, body, now, strings.Repeat("n", SIGNATURE_MAX_NONCE_LENGTH+1)), "nonce too long"},
+		{"missing headers", unsigned, "missing signature headers"},
+		{"message with session", newSignedRequestTo(signer, http.MethodPost, "/message?sessionId=abc", nil, body, now, "n6"), ""},
+		{"streamable with session", newSignedRequestTo(signer, http.MethodPost, "/mcp", http.Header{server.HeaderKeySessionID: {"abc"}}, body, now, "n7"), ""},
+		{"another session ID in the query", retarget(message, http.MethodPost, "/message?sessionId=xyz", nil, body), "invalid signature"},
+		{"extra query parameter", retarget(message, http.MethodPost, "/message?sessionId=abc&x=1", nil, body), "invalid signature"},
+		{"another path", retarget(message, http.MethodPost, "/mcp/message?sessionId=abc", nil, body), "invalid signature"},
+		{"another session ID header", retarget(streamable, http.MethodPost, "/mcp", http.Header{server.HeaderKeySessionID: {"xyz"}}, body), "invalid signature"},
+		{"session ID header removed", retarget(streamable, http.MethodPost, "/mcp", nil, body), "invalid signature"},
+		{"another method", retarget(deletion, http.MethodGet, "/mcp", http.Header{server.HeaderKeySessionID: {"abc"}}, ""), "invalid signature"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if reason := signer.verify(tt.request); reason != tt.wantReason {
+				t.Errorf("verify() = %q, want %q", reason, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestRequestSignerRejectedNonceIsNotRecorded(t *testing.T) {
+	signer := newRequestSigner("shared-secret")
+	other := newRequestSigner("other-secret")
+	now := time.Now()
+	if reason := signer.verify(newSignedRequest(other, http.MethodPost, "{}", now, "n1")); reason == "" {
+		t.Fatal("verify() accepted a request signed with another secret")
+	}
+	if reason := signer.verify(newSignedRequest(signer, http.MethodPost, "{}", now, "n1")); reason != "" {
+		t.Errorf("verify() = %q after a forged request used the nonce, want \"\"", reason)
+	}
+}
+
+func TestUseNonceExpiry(t *testing.T) {
+	signer := newRequestSigner("shared-secret")
+	now := time.Now()
+	if reason := signer.useNonce("a", now); reason != "" {
+		t.Fatalf("useNonce(a) = %q", reason)
+	}
+	if reason := signer.useNonce("b", now.Add(time.Minute)); reason != "" {
+		t.Fatalf("useNonce(b) = %q", reason)
+	}
+	if reason := signer.useNonce("a", now.Add(time.Minute)); reason != "nonce already used" {
+		t.Errorf("useNonce(a) within the window = %q, want nonce already used", reason)
+	}
+
+	// Past a's expiry, but not b's.
+	later := now.Add(2*SIGNATURE_MAX_SKEW + time.Second)
+	if reason := signer.useNonce("c", later); reason != "" {
+		t.Fatalf("useNonce(c) = %q", reason)
+	}
+	if signer.nonces["a"] {
+		t.Error("expired nonce a is still remembered")
+	}
+	if !signer.nonces["b"] {
+		t.Error("nonce b was dropped before it expired")
+	}
+	if len(signer.expiry) != len(signer.nonces) {
+		t.Errorf("expiry queue has %d entries for %d nonces", len(signer.expiry), len(signer.nonces))
+	}
+}
+
+func TestUseNonceCap(t *testing.T) {
+	signer := newRequestSigner("shared-secret")
+	now := time.Now()
+	for i := 0; i < SIGNATURE_MAX_NONCES; i++ {
+		if reason := signer.useNonce(strconv.Itoa(i), now); reason != "" {
+			t.Fatalf("useNonce(%d) = %q", i, reason)
+		}
+	}
+	if reason := signer.useNonce("one-too-many", now); reason == "" {
+		t.Error("useNonce() accepted a nonce beyond SIGNATURE_MAX_NONCES")
+	}
+	if reason := signer.useNonce("after-expiry", now.Add(2*SIGNATURE_MAX_SKEW+time.Second)); reason != "" {
+		t.Errorf("useNonce() after every nonce expired = %q, want \"\"", reason)
+	}
+}
+
+func TestRequestSignerMiddleware(t *testing.T) {
+	signer := newRequestSigner("shared-secret")
+	handler := signer.middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	for _, method := range []string{http.MethodPost, http.MethodGet, http.MethodDelete} {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(method, "/mcp", nil))
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("unsigned %s: status = %d, want %d", method, w.Code, http.StatusUnauthorized)
+		}
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, newSignedRequest(signer, http.MethodGet, "", time.Now(), "n1"))
+	if w.Code != http.StatusOK {
+		t.Errorf("signed GET: status = %d, want %d", w.Code, http.StatusOK)
+	}
+}
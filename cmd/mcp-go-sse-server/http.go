@@ -0,0 +1,158 @@
+package main
+
+import (
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"net/http"
+	"net/http/pprof"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/server"
+
+	mcpserver "mcp-go-sse-server/pkg/server"
+)
+
+// STREAMABLE_HTTP_PATH is the single endpoint of the streamable HTTP transport.
+const STREAMABLE_HTTP_PATH = "/mcp"
+
+// normalizeBasePath turns a --base-path value into "" or "/prefix" without a
+// trailing slash, so it can be prepended to every route.
+func normalizeBasePath(basePath string) string {
+	basePath = strings.Trim(basePath, "/")
+	if basePath == "" {
+		return ""
+	}
+	return "/" + basePath
+}
+
+// newOpsMux returns a mux with the operational endpoints shared by every HTTP
+// transport, mounted under basePath. /admin/sessions lists the session IDs
+// that messages are routed by, and /debug/vars the process metrics, so unless
+// the mux is served on the private --admin-port they are only mounted when an
+// admin token protects them.
+func newOpsMux(basePath string, sessions *sessionTracker, approvals *approvalGate, private bool) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc(basePath+"/healthz", handleHealthz)
+	mux.HandleFunc(basePath+"/readyz", handleReadyz(map[string]readinessCheck{
+		"sessions": sessions.ready,
+	}))
+	if private || sessions.adminToken != "" {
+		mux.HandleFunc(basePath+"/admin/sessions", sessions.handleAdmin)
+		mux.HandleFunc(basePath+"/debug/vars", handleMetrics(sessions.adminToken))
+	}
+	approvals.handle(mux, basePath)
+	return mux
+}
+
+// handleMetrics serves the expvar variables like expvar.Handler, except for
+// cmdline: the command line holds every secret passed as a flag.
+func handleMetrics(adminToken string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !authorizeAdmin(w, r, adminToken) {
+			return
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		fmt.Fprintf(w, "{\n")
+		first := true
+		expvar.Do(func(kv expvar.KeyValue) {
+			if kv.Key == "cmdline" {
+				return
+			}
+			if !first {
+				fmt.Fprintf(w, ",\n")
+			}
+			first = false
+			fmt.Fprintf(w, "%q: %s", kv.Key, kv.Value)
+		})
+		fmt.Fprintf(w, "\n}\n")
+	}
+}
+
+// newAdminMux returns the mux served on --admin-port: the operational
+// endpoints plus pprof, which is never exposed on the MCP listener. pprof
+// requires the admin token when one is set, and leaves out cmdline, which
+// would reveal the secrets passed as flags.
+func newAdminMux(sessions *sessionTracker, approvals *approvalGate) *http.ServeMux {
+	mux := newOpsMux("", sessions, approvals, true)
+	mux.HandleFunc("/debug/pprof/", requireAdmin(sessions.adminToken, pprof.Index))
+	mux.HandleFunc("/debug/pprof/cmdline", http.NotFound)
+	mux.HandleFunc("/debug/pprof/profile", requireAdmin(sessions.adminToken, pprof.Profile))
+	mux.HandleFunc("/debug/pprof/symbol", requireAdmin(sessions.adminToken, pprof.Symbol))
+	mux.HandleFunc("/debug/pprof/trace", requireAdmin(sessions.adminToken, pprof.Trace))
+	return mux
+}
+
+// requireAdmin guards next with authorizeAdmin.
+func requireAdmin(adminToken string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if authorizeAdmin(w, r, adminToken) {
+			next(w, r)
+		}
+	}
+}
+
+// discoveryDocument is served at the root path so clients pointed at the bare
+// server URL can find the endpoints they should use instead.
+type discoveryDocument struct {
+	baseURL    string
+	trustProxy bool
+	transports []string
+	// endpoints maps endpoint names to their paths.
+	endpoints map[string]string
+}
+
+// register serves the document at basePath, with and without a trailing
+// slash, or at / without a base path.
+func (d *discoveryDocument) register(mux *http.ServeMux, basePath string) {
+	mux.HandleFunc(basePath+"/{$}", d.handle)
+	if basePath != "" {
+		mux.HandleFunc(basePath, d.handle)
+	}
+}
+
+func (d *discoveryDocument) handle(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	baseURL := d.baseURL
+	if d.trustProxy {
+		baseURL = forwardedBaseURL(r)
+	}
+	endpoints := make(map[string]string, len(d.endpoints))
+	for name, path := range d.endpoints {
+		endpoints[name] = baseURL + path
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"name":       mcpserver.SERVER_NAME,
+		"version":    mcpserver.SERVER_VERSION,
+		"transports": d.transports,
+		"endpoints":  endpoints,
+	})
+}
+
+// handleSSE routes the SSE and message endpoints of the SSE server. With
+// trustProxy the server must announce a relative message endpoint, which is
+// then completed from each request's X-Forwarded-* headers.
+func handleSSE(
+	mux *http.ServeMux,
+	sseServer *server.SSEServer,
+	sessions *sessionTracker,
+	limiter *clientRateLimiter,
+	idle *idleSessions,
+	router *sessionRouter,
+	buffering *sseBuffering,
+	auth *authenticator,
+	signer *requestSigner,
+	trustProxy bool,
+) {
+	var sseHandler http.Handler = sseServer
+	if trustProxy {
+		sseHandler = trustProxyMiddleware(sseHandler)
+	}
+	sseHandler = buffering.middleware(sseHandler)
+	mux.Handle(sseServer.CompleteSsePath(), auth.middleware(sessions.middleware(idle.sseMiddleware(sseHandler))))
+	mux.Handle(sseServer.CompleteMessagePath(), auth.middleware(signer.middleware(router.middleware(limiter.middleware(idle.messageMiddleware(sseServer))))))
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"expvar"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/server"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+
+	mcpserver "mcp-go-sse-server/pkg/server"
+)
+
+func TestHandleMetrics(t *testing.T) {
+	expvar.NewInt("test_metric").Set(7)
+	tests := []struct {
+		name          string
+		adminToken    string
+		authorization string
+		wantStatus    int
+	}{
+		{"no token configured", "", "", http.StatusOK},
+		{"valid token", "admintok", "Bearer admintok", http.StatusOK},
+		{"missing token", "admintok", "", http.StatusUnauthorized},
+		{"wrong token", "admintok", "Bearer nope", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/debug/vars", nil)
+			if tt.authorization != "" {
+				r.Header.Set("Authorization", tt.authorization)
+			}
+			w := httptest.NewRecorder()
+			handleMetrics(tt.adminToken)(w, r)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if w.Code != http.StatusOK {
+				return
+			}
+			var vars map[string]json.RawMessage
+			if err := json.Unmarshal(w.Body.Bytes(), &vars); err != nil {
+				t.Fatalf("invalid JSON: %v\n%s", err, w.Body)
+			}
+			if _, ok := vars["cmdline"]; ok {
+				t.Error("metrics include cmdline")
+			}
+			if string(vars["test_metric"]) != "7" || vars["memstats"] == nil {
+				t.Errorf("metrics are missing variables: %s", w.Body)
+			}
+		})
+	}
+}
+
+func TestOpsMuxAdminEndpoints(t *testing.T) {
+	tests := []struct {
+		name       string
+		adminToken string
+		private    bool
+		wantStatus int
+	}{
+		{"public listener without token", "", false, http.StatusNotFound},
+		{"public listener with token", "admintok", false, http.StatusUnauthorized},
+		{"admin listener", "", true, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sessions := &sessionTracker{registry: newSessionRegistry(), adminToken: tt.adminToken}
+			mux := newOpsMux("/mcp", sessions, nil, tt.private)
+			for _, path := range []string{"/mcp/admin/sessions", "/mcp/debug/vars"} {
+				w := httptest.NewRecorder()
+				mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+				if w.Code != tt.wantStatus {
+					t.Errorf("GET %s: status = %d, want %d", path, w.Code, tt.wantStatus)
+				}
+			}
+		})
+	}
+}
+
+func TestSSEOverH2C(t *testing.T) {
+	sseServer := server.NewSSEServer(mcpserver.NewMCPServer(nil), server.WithBaseURL("http://localhost"))
+	// The middlewares wrapping the response writer must keep flushing each
+	// event through to the HTTP/2 stream.
+	var handler http.Handler = sseServer
+	handler = gzipMiddleware(handler)
+	handler = headersMiddleware(handler, http.Header{"X-Test": {"1"}})
+	handler = streamDeadlineMiddleware(handler)
+	handler = securityHeadersMiddleware(handler)
+	srv := httptest.NewServer(h2c.NewHandler(handler, &http2.Server{}))
+	defer srv.Close()
+
+	// Prior knowledge: speak HTTP/2 over cleartext without an upgrade.
+	client := &http.Client{Transport: &http2.Transport{
+		AllowHTTP: true,
+		DialTLSContext: func(ctx context.Context, network string, addr string, cfg *tls.Config) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, network, addr)
+		},
+	}}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/sse", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Accept-Encoding", "gzip")
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.ProtoMajor != 2 {
+		t.Fatalf("protocol = %s, want HTTP/2", resp.Proto)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Content-Type = %q, want text/event-stream", ct)
+	}
+
+	// The stream stays open, so the event is only read if it was flushed.
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		if scanner.Text() != "event: endpoint" {
+			continue
+		}
+		if !scanner.Scan() || !strings.HasPrefix(scanner.Text(), "data: ") || !strings.Contains(scanner.Text(), "sessionId=") {
+			t.Fatalf("endpoint event data = %q", scanner.Text())
+		}
+		return
+	}
+	t.Fatalf("stream ended before the endpoint event: %v", scanner.Err())
+}
+
+func TestAdminMuxPprof(t *testing.T) {
+	tests := []struct {
+		name          string
+		adminToken    string
+		path          string
+		authorization string
+		wantStatus    int
+	}{
+		{"index without token configured", "", "/debug/pprof/", "", http.StatusOK},
+		{"index with token", "admintok", "/debug/pprof/", "Bearer admintok", http.StatusOK},
+		{"index missing token", "admintok", "/debug/pprof/", "", http.StatusUnauthorized},
+		{"heap missing token", "admintok", "/debug/pprof/heap", "", http.StatusUnauthorized},
+		{"symbol missing token", "admintok", "/debug/pprof/symbol", "", http.StatusUnauthorized},
+		{"cmdline", "", "/debug/pprof/cmdline", "", http.StatusNotFound},
+		{"cmdline with token", "admintok", "/debug/pprof/cmdline", "Bearer admintok", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sessions := &sessionTracker{registry: newSessionRegistry(), adminToken: tt.adminToken}
+			r := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.authorization != "" {
+				r.Header.Set("Authorization", tt.authorization)
+			}
+			w := httptest.NewRecorder()
+			newAdminMux(sessions, nil).ServeHTTP(w, r)
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestDiscoveryDocumentRoutes(t *testing.T) {
+	tests := []struct {
+		name       string
+		basePath   string
+		path       string
+		wantStatus int
+	}{
+		{"root", "", "/", http.StatusOK},
+		{"other path", "", "/other", http.StatusNotFound},
+		{"base path with slash", "/mcp/dbserver", "/mcp/dbserver/", http.StatusOK},
+		{"base path without slash", "/mcp/dbserver", "/mcp/dbserver", http.StatusOK},
+		{"root under a base path", "/mcp/dbserver", "/", http.StatusNotFound},
+		{"below the base path", "/mcp/dbserver", "/mcp/dbserver/other", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			discovery := &discoveryDocument{
+				baseURL:    "https://mcp.example.com",
+				transports: []string{"sse"},
+				endpoints:  map[string]string{"sse": tt.basePath + "/sse"},
+			}
+			mux := http.NewServeMux()
+			discovery.register(mux, tt.basePath)
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if w.Code != http.StatusOK {
+				return
+			}
+			var document struct {
+				Endpoints map[string]string `json:"endpoints"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &document); err != nil {
+				t.Fatal(err)
+			}
+			if want := "https://mcp.example.com" + tt.basePath + "/sse"; document.Endpoints["sse"] != want {
+				t.Errorf("sse endpoint = %q, want %q", document.Endpoints["sse"], want)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type idleSessionKey struct{}
+
+// idleSession is an open SSE stream and the time its client last posted a
+// message.
+type idleSession struct {
+	id         string
+	cancel     context.CancelFunc
+	lastActive time.Time
+}
+
+// idleSessions closes SSE sessions whose client has not posted a message for
+// longer than timeout. Closing the stream unregisters the session, which
+// releases its per-session state.
+type idleSessions struct {
+	timeout  time.Duration
+	mu       sync.Mutex
+	sessions map[string]*idleSession
+}
+
+func newIdleSessions(timeout time.Duration) *idleSessions {
+	s := &idleSessions{
+		timeout:  timeout,
+		sessions: make(map[string]*idleSession),
+	}
+	go s.sweep()
+	return s
+}
+
+// sseMiddleware wraps the SSE endpoint so the stream can be cancelled. A nil
+// *idleSessions leaves the handler as is.
+func (s *idleSessions) sseMiddleware(next http.Handler) http.Handler {
+	if s == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithCancel(r.Context())
+		defer cancel()
+		session := &idleSession{cancel: cancel}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(ctx, idleSessionKey{}, session)))
+
+		s.mu.Lock()
+		delete(s.sessions, session.id)
+		s.mu.Unlock()
+	})
+}
+
+// generateSessionID is the SSE server's session ID generator. It records the
+// new ID against the stream opened by sseMiddleware.
+func (s *idleSessions) generateSessionID(ctx context.Context, r *http.Request) (string, error) {
+	id := uuid.New().String()
+	if session, ok := ctx.Value(idleSessionKey{}).(*idleSession); ok {
+		s.mu.Lock()
+		session.id = id
+		session.lastActive = time.Now()
+		s.sessions[id] = session
+		s.mu.Unlock()
+	}
+	return id, nil
+}
+
+// messageMiddleware marks the posting session as active.
+func (s *idleSessions) messageMiddleware(next http.Handler) http.Handler {
+	if s == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if id := r.URL.Query().Get("sessionId"); id != "" {
+			s.mu.Lock()
+			if session, ok := s.sessions[id]; ok {
+				session.lastActive = time.Now()
+			}
+			s.mu.Unlock()
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *idleSessions) sweep() {
+	for range time.Tick(max(s.timeout/2, time.Second)) {
+		s.mu.Lock()
+		for id, session := range s.sessions {
+			if time.Since(session.lastActive) > s.timeout {
+				log.Printf("Closing SSE session %s after %s without activity", id, s.timeout)
+				session.cancel()
+				delete(s.sessions, id)
+			}
+		}
+		s.mu.Unlock()
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestIdleSessions(t *testing.T) {
+	idle := newIdleSessions(200 * time.Millisecond)
+	opened := make(chan string, 2)
+	closed := make(chan string, 2)
+	// Stands in for the SSE server, which generates the session ID and then
+	// holds the stream until its request context ends.
+	stream := idle.sseMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, err := idle.generateSessionID(r.Context(), r)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		opened <- id
+		<-r.Context().Done()
+		closed <- id
+	}))
+	message := idle.messageMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	for i := 0; i < 2; i++ {
+		go stream.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/sse", nil))
+	}
+	active, quiet := <-opened, <-opened
+	if active == quiet {
+		t.Fatal("both streams got the same session ID")
+	}
+
+	deadline := time.After(5 * time.Second)
+	for {
+		message.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/message?sessionId="+active, nil))
+		select {
+		case id := <-closed:
+			if id != quiet {
+				t.Fatalf("closed session %s, which was posting messages", id)
+			}
+			idle.mu.Lock()
+			_, activeTracked := idle.sessions[active]
+			_, quietTracked := idle.sessions[quiet]
+			idle.mu.Unlock()
+			if !activeTracked || quietTracked {
+				t.Errorf("tracked after the sweep: active %v, quiet %v, want only the active one", activeTracked, quietTracked)
+			}
+			return
+		case <-deadline:
+			t.Fatal("idle session was not closed")
+		case <-time.After(20 * time.Millisecond):
+		}
+	}
+}
+
+func TestIdleSessionsForgetClosedStreams(t *testing.T) {
+	idle := newIdleSessions(time.Hour)
+	stream := idle.sseMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		idle.generateSessionID(r.Context(), r)
+	}))
+	stream.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/sse", nil))
+	if len(idle.sessions) != 0 {
+		t.Errorf("%d sessions tracked after their stream closed, want 0", len(idle.sessions))
+	}
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// JWKS_REFRESH_INTERVAL is how long fetched keys are trusted before the JWKS
+// is fetched again. An unknown key ID triggers an earlier fetch, at most once
+// per JWKS_MIN_REFRESH_INTERVAL, so rotated keys are picked up quickly.
+const (
+	JWKS_REFRESH_INTERVAL     = time.Hour
+	JWKS_MIN_REFRESH_INTERVAL = time.Minute
+)
+
+// jwtValidator checks bearer tokens against the keys published at a JWKS URL.
+type jwtValidator struct {
+	url    string
+	client *http.Client
+	parser *jwt.Parser
+
+	mu   sync.Mutex
+	keys map[string]crypto.PublicKey
+	// fetched is when the last fetch started.
+	fetched time.Time
+	// refreshing is closed when the running fetch ends, and nil when no fetch
+	// is running.
+	refreshing chan struct{}
+}
+
+func newJWTValidator(jwksURL string, issuer string, audience string) *jwtValidator {
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(30 * time.Second),
+	}
+	if issuer != "" {
+		opts = append(opts, jwt.WithIssuer(issuer))
+	}
+	if audience != "" {
+		opts = append(opts, jwt.WithAudience(audience))
+	}
+	return &jwtValidator{
+		url:    jwksURL,
+		client: &http.Client{Timeout: 10 * time.Second},
+		parser: jwt.NewParser(opts...),
+	}
+}
+
+// validate checks the token signature, issuer, audience and expiry, and
+// returns its claims.
+func (v *jwtValidator) validate(ctx context.Context, token string) (map[string]any, error) {
+	claims := jwt.MapClaims{}
+	_, err := v.parser.ParseWithClaims(token, claims, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		return v.key(ctx, kid)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// key returns the public key with the given ID. A stale cache is refreshed
+// in the background while its keys stay in use; a token with an unknown key
+// ID waits for a fetch, which is shared with concurrent callers and started
+// at most once per JWKS_MIN_REFRESH_INTERVAL, so such tokens cannot hold up
+// other requests or flood the identity provider.
+func (v *jwtValidator) key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	v.mu.Lock()
+	key, ok := v.keys[kid]
+	if ok && time.Since(v.fetched) < JWKS_REFRESH_INTERVAL {
+		v.mu.Unlock()
+		return key, nil
+	}
+	done := v.refresh()
+	v.mu.Unlock()
+	if ok {
+		return key, nil
+	}
+	if done != nil {
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		v.mu.Lock()
+		key, ok = v.keys[kid]
+		v.mu.Unlock()
+		if ok {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown key ID %q", kid)
+}
+
+// refresh starts fetching the JWKS unless a fetch is running or the last one
+// started less than JWKS_MIN_REFRESH_INTERVAL ago. It returns a channel
+// closed when the running fetch ends, or nil when there is none. The fetch
+// does not use the caller's context, since other callers may wait for it.
+// Called with v.mu held.
+func (v *jwtValidator) refresh() <-chan struct{} {
+	if v.refreshing != nil || time.Since(v.fetched) < JWKS_MIN_REFRESH_INTERVAL {
+		return v.refreshing
+	}
+	v.fetched = time.Now()
+	done := make(chan struct{})
+	v.refreshing = done
+	go func() {
+		defer close(done)
+		keys, err := v.fetch(context.Background())
+		v.mu.Lock()
+		defer v.mu.Unlock()
+		v.refreshing = nil
+		if err != nil {
+			log.Printf("Failed to fetch JWKS: %v", err)
+			return
+		}
+		v.keys = keys
+	}()
+	return done
+}
+
+func (v *jwtValidator) fetch(ctx context.Context) (map[string]crypto.PublicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, err
+	}
+	keys := map[string]crypto.PublicKey{}
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			log.Printf("Skipping JWKS key %q: %v", jwk.Kid, err)
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+	return keys, nil
+}
+
+// jsonWebKey is the subset of RFC 7517 needed for signature verification.
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (k jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 key size")
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
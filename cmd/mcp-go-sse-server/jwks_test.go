@@ -0,0 +1,198 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	mcpserver "mcp-go-sse-server/pkg/server"
+)
+
+const (
+	testIssuer   = "https://idp.example.com"
+	testAudience = "https://mcp.example.com"
+)
+
+// newTestJWKS serves the public half of key under key ID kid.
+func newTestJWKS(t *testing.T, kid string, key *ecdsa.PrivateKey) *httptest.Server {
+	t.Helper()
+	encode := func(b []byte) string { return base64.RawURLEncoding.EncodeToString(b) }
+	jwks := map[string]any{"keys": []map[string]string{{
+		"kty": "EC",
+		"kid": kid,
+		"crv": "P-256",
+		"x":   encode(key.PublicKey.X.FillBytes(make([]byte, 32))),
+		"y":   encode(key.PublicKey.Y.FillBytes(make([]byte, 32))),
+	}}}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(jwks)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func newTestKey(t *testing.T) *ecdsa.PrivateKey {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func signTestToken(t *testing.T, key *ecdsa.PrivateKey, kid string, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func TestAuthenticateJWT(t *testing.T) {
+	key := newTestKey(t)
+	jwks := newTestJWKS(t, "k1", key)
+	auth, err := newAuthenticator("s3cretkey", "", "", "", newJWTValidator(jwks.URL, testIssuer, testAudience))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	claims := func(overrides jwt.MapClaims) jwt.MapClaims {
+		c := jwt.MapClaims{
+			"sub": "bob",
+			"iss": testIssuer,
+			"aud": testAudience,
+			"exp": now.Add(time.Hour).Unix(),
+		}
+		for k, v := range overrides {
+			c[k] = v
+		}
+		return c
+	}
+	hmacToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims(nil)).SignedString([]byte("s3cretkey"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		token  string
+		wantOK bool
+	}{
+		{"valid", signTestToken(t, key, "k1", claims(nil)), true},
+		{"audience list", signTestToken(t, key, "k1", claims(jwt.MapClaims{"aud": []string{"https://other.example.com", testAudience}})), true},
+		{"expired", signTestToken(t, key, "k1", claims(jwt.MapClaims{"exp": now.Add(-time.Hour).Unix()})), false},
+		{"without expiry", signTestToken(t, key, "k1", claims(jwt.MapClaims{"exp": nil})), false},
+		{"wrong audience", signTestToken(t, key, "k1", claims(jwt.MapClaims{"aud": "https://other.example.com"})), false},
+		{"without audience", signTestToken(t, key, "k1", claims(jwt.MapClaims{"aud": nil})), false},
+		{"wrong issuer", signTestToken(t, key, "k1", claims(jwt.MapClaims{"iss": "https://evil.example.com"})), false},
+		{"unknown key ID", signTestToken(t, key, "k2", claims(nil)), false},
+		{"signed by another key", signTestToken(t, newTestKey(t), "k1", claims(nil)), false},
+		{"HMAC algorithm", hmacToken, false},
+		{"malformed", "not.a.jwt", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, ok := auth.authenticate(context.Background(), "Bearer "+tt.token)
+			if ok != tt.wantOK {
+				t.Fatalf("authenticate() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			claims, _ := mcpserver.ClaimsFromContext(ctx)
+			if claims["sub"] != "bob" {
+				t.Errorf("sub = %v, want bob", claims["sub"])
+			}
+		})
+	}
+}
+
+func TestJWTValidatorUnknownKeyDoesNotBlock(t *testing.T) {
+	key := newTestKey(t)
+	jwks := newTestJWKS(t, "k1", key)
+	var fetches atomic.Int32
+	release := make(chan struct{})
+	blocked := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The first fetch succeeds, later ones hang until released.
+		if fetches.Add(1) > 1 {
+			<-release
+		}
+		resp, err := http.Get(jwks.URL)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer resp.Body.Close()
+		io.Copy(w, resp.Body)
+	}))
+	defer blocked.Close()
+	defer close(release)
+
+	validator := newJWTValidator(blocked.URL, testIssuer, testAudience)
+	claims := jwt.MapClaims{"sub": "bob", "iss": testIssuer, "aud": testAudience, "exp": time.Now().Add(time.Hour).Unix()}
+	valid := signTestToken(t, key, "k1", claims)
+	unknown := signTestToken(t, key, "k2", claims)
+	if _, err := validator.validate(context.Background(), valid); err != nil {
+		t.Fatalf("validate() = %v", err)
+	}
+
+	// Let tokens with an unknown key ID trigger a fetch, which hangs.
+	validator.mu.Lock()
+	validator.fetched = time.Now().Add(-2 * JWKS_MIN_REFRESH_INTERVAL)
+	validator.mu.Unlock()
+	var waiting sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		waiting.Add(1)
+		go func() {
+			defer waiting.Done()
+			if _, err := validator.validate(context.Background(), unknown); err == nil {
+				t.Error("validate() accepted a token with an unknown key ID")
+			}
+		}()
+	}
+	for fetches.Load() < 2 {
+		time.Sleep(time.Millisecond)
+	}
+
+	start := time.Now()
+	if _, err := validator.validate(context.Background(), valid); err != nil {
+		t.Errorf("validate() during a JWKS fetch = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("validate() of a known key waited %s for the JWKS fetch", elapsed)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := validator.validate(ctx, unknown); err == nil {
+		t.Error("validate() accepted a token with an unknown key ID")
+	}
+
+	release <- struct{}{}
+	waiting.Wait()
+	if got := fetches.Load(); got != 2 {
+		t.Errorf("JWKS fetched %d times, want 2: concurrent unknown key IDs share one fetch", got)
+	}
+	// Within JWKS_MIN_REFRESH_INTERVAL, unknown key IDs do not fetch again.
+	if _, err := validator.validate(context.Background(), unknown); err == nil {
+		t.Error("validate() accepted a token with an unknown key ID")
+	}
+	if got := fetches.Load(); got != 2 {
+		t.Errorf("JWKS fetched %d times, want 2 within the minimum refresh interval", got)
+	}
+}
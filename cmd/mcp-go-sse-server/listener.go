@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// newListener opens the listener for the HTTP transports. An empty listen
+// address listens on the TCP address addr; unix:///path/to.sock listens on a
+// Unix domain socket created with socketMode permissions.
+func newListener(listen string, addr string, socketMode string) (net.Listener, error) {
+	if listen == "" {
+		return net.Listen("tcp", addr)
+	}
+	path, ok := strings.CutPrefix(listen, "unix://")
+	if !ok {
+		return nil, fmt.Errorf("unsupported listen address %q, expected unix:///path/to.sock", listen)
+	}
+	mode, err := strconv.ParseUint(socketMode, 8, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid socket mode %q: %w", socketMode, err)
+	}
+
+	// Remove a socket left behind by a previous run, but never another kind
+	// of file.
+	if info, err := os.Stat(path); err == nil {
+		if info.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("%s exists and is not a socket", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("failed to remove stale socket: %w", err)
+		}
+	}
+
+	// The socket file is unlinked when the listener is closed.
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, os.FileMode(mode)); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to set socket permissions: %w", err)
+	}
+	return listener, nil
+}
@@ -0,0 +1,619 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/mark3labs/mcp-go/server"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+
+	mcpserver "mcp-go-sse-server/pkg/server"
+)
+
+func main() {
+	if len(os.Args) > 1 && os.Args[1] == "bench" {
+		runBench(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "decrypt" {
+		runDecrypt(os.Args[2:])
+		return
+	}
+
+	var transport string
+	var port string
+	var baseURL string
+	var omitPort bool
+	var notificationRoutes string
+	var maxSessions int
+	var tlsCert string
+	var tlsKey string
+	var ssePath string
+	var messagePath string
+	var shutdownTimeout time.Duration
+	var trustProxy bool
+	var listen string
+	var socketMode string
+	var accessLog bool
+	var rateLimit float64
+	var rateBurst int
+	var rateLimitKey string
+	var sseKeepAlive time.Duration
+	var basePath string
+	var listenAddr string
+	var h2cEnabled bool
+	var gzipEnabled bool
+	var sessionIdleTimeout time.Duration
+	var adminToken string
+	var adminPort string
+	var acmeDomain string
+	var acmeCacheDir string
+	var acmeEmail string
+	var maxRequestBytes int64
+	var grpcPort string
+	var sessionStoreURL string
+	var advertiseURL string
+	var readHeaderTimeout time.Duration
+	var readTimeout time.Duration
+	var writeTimeout time.Duration
+	var idleTimeout time.Duration
+	var sseBufferSize int
+	var sseBufferPolicy string
+	var apiKeys string
+	var apiKeysFile string
+	var toolPolicyFile string
+	var auditLogPath string
+	var redactRules string
+	var encryptionKeys string
+	var approvalTools string
+	var middlewareOrder string
+	var toolTimeout time.Duration
+	var approvalTimeout time.Duration
+	var maxResultBytes int
+	var toolQuota string
+	var enableTools string
+	var disableTools string
+	var hmacSecret string
+	var allowedOrigins string
+	var basicAuth string
+	var basicAuthFile string
+	var jwksURL string
+	var jwtIssuer string
+	var jwtAudience string
+	var oauthIssuer string
+	var oauthResource string
+	var oauthClientID string
+	headers := headerFlags{}
+	flag.StringVar(&transport, "transport", "stdio", "Transport type (stdio, sse, streamable-http or grpc). Use a comma-separated list, or both for stdio,sse, to serve several at once.")
+	flag.StringVar(&port, "port", "3001", "Port to run the MCP server on.")
+	flag.StringVar(&grpcPort, "grpc-port", "3002", "Port of the gRPC transport.")
+	flag.StringVar(&baseURL, "baseurl", "http://localhost", "Base URL for the server.")
+	flag.BoolVar(&omitPort, "omitPort", false, "Not append port to base URL. Useful when server is served through a domain name.")
+	flag.StringVar(&notificationRoutes, "notification-routes", "", "JSON file mapping client notification methods to actions (log, webhook, tool).")
+	flag.IntVar(&maxSessions, "max-sessions", 0, "Maximum number of concurrent SSE, streamable HTTP and gRPC sessions (0 means unlimited).")
+	flag.StringVar(&tlsCert, "tls-cert", "", "TLS certificate file. Serves HTTPS, and gRPC over TLS, when set together with --tls-key.")
+	flag.StringVar(&tlsKey, "tls-key", "", "TLS private key file. Serves HTTPS, and gRPC over TLS, when set together with --tls-cert.")
+	flag.StringVar(&acmeDomain, "acme-domain", "", "Obtain TLS certificates for this comma-separated list of domains from Let's Encrypt. Requires --port 443.")
+	flag.StringVar(&acmeCacheDir, "acme-cache-dir", "acme-cache", "Directory where --acme-domain certificates are cached.")
+	flag.StringVar(&acmeEmail, "acme-email", "", "Contact email registered with Let's Encrypt for --acme-domain.")
+	flag.StringVar(&ssePath, "sse-path", "/sse", "Path of the SSE endpoint.")
+	flag.StringVar(&messagePath, "message-path", "/message", "Path of the message endpoint.")
+	flag.StringVar(&basePath, "base-path", "", "Path prefix for every HTTP route, e.g. /mcp/dbserver when mounted behind an ingress.")
+	flag.DurationVar(&sessionIdleTimeout, "session-idle-timeout", 0, "Close SSE and streamable HTTP sessions whose client sent no message for this long (0 disables).")
+	flag.StringVar(&adminPort, "admin-port", "", "Serve /healthz, /readyz, /admin/sessions, /debug/vars and /debug/pprof on this port of 127.0.0.1 instead of the MCP listener. Give host:port to bind another address, which requires --admin-token.")
+	flag.StringVar(&sessionStoreURL, "session-store", "", "Redis URL (redis://host:6379/0) of the session store shared by replicas. Messages for a session held by another replica are forwarded to it.")
+	flag.StringVar(&advertiseURL, "advertise-url", "", "URL at which other replicas reach this one, e.g. http://10.0.0.5:3001. Required with --session-store.")
+	flag.StringVar(&apiKeys, "api-keys", "", "Comma-separated API keys. When set, SSE, streamable HTTP and gRPC clients must send one as a bearer token or api_key query parameter.")
+	flag.StringVar(&apiKeysFile, "api-keys-file", "", "File with one API key per line, in addition to --api-keys.")
+	flag.StringVar(&enableTools, "enable-tools", "", "Comma-separated tools to expose. All tools are exposed when empty.")
+	flag.StringVar(&disableTools, "disable-tools", "", "Comma-separated tools to remove, applied after --enable-tools.")
+	flag.StringVar(&auditLogPath, "audit-log", "", "Append one JSON line per tool call to this file (- for stderr), with session, principal, tool, arguments, result size, duration and error.")
+	flag.IntVar(&maxResultBytes, "max-result-bytes", 0, "Maximum bytes of text, image and audio data in a tool result; larger results are truncated with a notice (0 means unlimited).")
+	flag.StringVar(&toolQuota, "tool-quota", "", "Per-principal tool call quotas as tool=count/window, e.g. echo=60/min,*=600/hour. Windows are s, min or hour.")
+	flag.DurationVar(&toolTimeout, "tool-timeout", 0, "Cancel tool calls running longer than this and return a tool error (0 means no limit).")
+	flag.StringVar(&middlewareOrder, "middleware", DEFAULT_MIDDLEWARE, "Ordered, outermost first, HTTP middlewares wrapping every route: request-id, security-headers, access-log, stream-deadline, headers, gzip, max-bytes, origin, and default for those enabled by their own flags.")
+	flag.StringVar(&approvalTools, "approval-tools", "", "Comma-separated tools whose calls are held until an operator approves them through /admin/approvals; * gates every tool. Requires --admin-token.")
+	flag.DurationVar(&approvalTimeout, "approval-timeout", 5*time.Minute, "How long a held tool call waits for approval before failing.")
+	flag.StringVar(&encryptionKeys, "encryption-keys", "", "AES-256 keys encrypting the audit log at rest, as comma-separated id=base64key pairs. The first key encrypts; the others only decrypt.")
+	flag.StringVar(&redactRules, "redact-rules", "", "JSON file of patterns and field names masked in tool results, the audit log and hook logging.")
+	flag.StringVar(&toolPolicyFile, "tool-policy", "", "JSON file mapping roles to the tools they may list and call, and principals to roles.")
+	flag.StringVar(&allowedOrigins, "allowed-origins", "", "Comma-separated origins, e.g. https://app.example.com, allowed to send browser requests besides the server's own; their hosts are also accepted in the Host header. * allows any origin and host.")
+	flag.StringVar(&hmacSecret, "hmac-secret", "", "Shared secret for HMAC-SHA256 signatures required on every message POSTed to the message and streamable HTTP endpoints.")
+	flag.StringVar(&basicAuth, "basic-auth", "", "user:password required as HTTP Basic credentials by SSE, streamable HTTP and gRPC clients.")
+	flag.StringVar(&basicAuthFile, "basic-auth-file", "", "File with one user:password pair per line, in addition to --basic-auth.")
+	flag.StringVar(&jwksURL, "jwks-url", "", "JWKS URL of the identity provider. When set, transport clients may authenticate with a JWT signed by one of its keys and issued for --jwt-audience, which is required.")
+	flag.StringVar(&jwtIssuer, "jwt-issuer", "", "Required iss claim of JWTs.")
+	flag.StringVar(&jwtAudience, "jwt-audience", "", "Required aud claim of JWTs.")
+	flag.StringVar(&oauthIssuer, "oauth-issuer", "", "OAuth 2.0 / OIDC authorization server issuing tokens for this server. Publishes the OAuth metadata endpoints and validates its JWTs.")
+	flag.StringVar(&oauthResource, "oauth-resource", "", "Resource identifier of this server in OAuth metadata, and default --jwt-audience. Defaults to the public base URL.")
+	flag.StringVar(&oauthClientID, "oauth-client-id", "", "Pre-registered public client ID handed out by a /register endpoint, for authorization servers without dynamic client registration.")
+	flag.StringVar(&adminToken, "admin-token", "", "Bearer token required by /admin/sessions, /admin/approvals and /debug/vars. Without it, /admin/sessions and /debug/vars are only served on --admin-port.")
+	flag.DurationVar(&readHeaderTimeout, "read-header-timeout", 10*time.Second, "Time allowed to read request headers (0 means no limit).")
+	flag.DurationVar(&readTimeout, "read-timeout", 30*time.Second, "Time allowed to read a whole request, excluding SSE streams (0 means no limit).")
+	flag.DurationVar(&writeTimeout, "write-timeout", 0, "Time allowed to write a response, excluding SSE streams (0 means no limit). Bounds tool calls answered with plain JSON.")
+	flag.DurationVar(&idleTimeout, "idle-timeout", 120*time.Second, "Time a keep-alive connection may stay idle between requests (0 means no limit).")
+	flag.DurationVar(&shutdownTimeout, "shutdown-timeout", 30*time.Second, "Time to wait for running tool calls on SIGINT/SIGTERM before closing connections.")
+	flag.BoolVar(&trustProxy, "trust-proxy", false, "Build the advertised message endpoint from X-Forwarded-Proto/Host instead of --baseurl. Only enable behind a reverse proxy that sets them.")
+	flag.StringVar(&listen, "listen", "", "Listen on a Unix domain socket (unix:///path/to.sock) instead of the TCP port.")
+	flag.StringVar(&listenAddr, "listen-addr", "", "TCP address to bind, e.g. 127.0.0.1:3001. Overrides --port, which otherwise binds all interfaces.")
+	flag.StringVar(&socketMode, "socket-mode", "0660", "Permissions of the Unix domain socket created by --listen.")
+	flag.BoolVar(&h2cEnabled, "h2c", false, "Accept cleartext HTTP/2 (h2c) alongside HTTP/1.1. HTTP/2 is always available over TLS.")
+	flag.BoolVar(&gzipEnabled, "gzip", false, "Gzip JSON-RPC responses for clients sending Accept-Encoding: gzip. SSE streams stay uncompressed.")
+	flag.BoolVar(&accessLog, "access-log", false, "Log every HTTP request as a JSON line on stderr.")
+	flag.Int64Var(&maxRequestBytes, "max-request-bytes", 4<<20, "Maximum size of a JSON-RPC request body in bytes (0 means unlimited).")
+	flag.Float64Var(&rateLimit, "rate-limit", 0, "Maximum messages per second per client (0 means unlimited).")
+	flag.IntVar(&rateBurst, "rate-burst", 10, "Number of messages a client may send in a burst above --rate-limit.")
+	flag.DurationVar(&sseKeepAlive, "sse-keepalive", 0, "Interval of ping events sent on idle SSE and streamable HTTP streams (0 disables them).")
+	flag.IntVar(&sseBufferSize, "sse-buffer-size", 0, "Maximum events queued per SSE stream for a slow client (0 keeps the transport's own blocking queue).")
+	flag.StringVar(&sseBufferPolicy, "sse-buffer-policy", SSE_BUFFER_DROP_OLDEST, "What to do when an SSE stream's queue is full: drop-oldest, drop-newest or disconnect. Only notifications are ever dropped.")
+	flag.Var(headers, "header", "Response header added to every HTTP response, as key=value. Can be repeated.")
+	flag.StringVar(&rateLimitKey, "rate-limit-key", "session", "Identify clients by MCP session (session) or by remote address (ip).")
+	flag.Parse()
+	if err := applyEnv(flag.CommandLine); err != nil {
+		log.Fatalf("Config error: %v", err)
+	}
+
+	useTLS := tlsCert != "" || tlsKey != ""
+	if useTLS && (tlsCert == "" || tlsKey == "") {
+		log.Fatalf("Config error: --tls-cert and --tls-key must be set together")
+	}
+	if useTLS && acmeDomain != "" {
+		log.Fatalf("Config error: --acme-domain cannot be combined with --tls-cert and --tls-key")
+	}
+	useTLS = useTLS || acmeDomain != ""
+	addr := ":" + port
+	if listenAddr != "" {
+		_, listenPort, err := net.SplitHostPort(listenAddr)
+		if err != nil {
+			log.Fatalf("Config error: invalid --listen-addr: %v", err)
+		}
+		addr = listenAddr
+		port = listenPort
+	}
+	if useTLS && strings.HasPrefix(baseURL, "http://") {
+		baseURL = "https://" + strings.TrimPrefix(baseURL, "http://")
+	}
+	var fullBaseURL string
+	if omitPort {
+		fullBaseURL = baseURL
+	} else {
+		fullBaseURL = baseURL + ":" + port
+	}
+	basePath = normalizeBasePath(basePath)
+	var buffering *sseBuffering
+	if sseBufferSize > 0 {
+		var err error
+		if buffering, err = newSSEBuffering(sseBufferSize, sseBufferPolicy); err != nil {
+			log.Fatalf("Config error: %v", err)
+		}
+	}
+	var oauth *oauthProvider
+	if oauthIssuer != "" {
+		var err error
+		if oauth, err = newOAuthProvider(oauthIssuer, oauthClientID); err != nil {
+			log.Fatalf("Config error: %v", err)
+		}
+		if jwksURL == "" {
+			jwksURL = oauth.jwksURI()
+		}
+		if jwtIssuer == "" {
+			jwtIssuer = oauthIssuer
+		}
+		// Tokens must be issued for this server, so one meant for another
+		// resource of the same authorization server is not accepted.
+		if oauthResource == "" {
+			oauthResource = fullBaseURL + basePath
+		}
+		if jwtAudience == "" {
+			jwtAudience = oauthResource
+		}
+		if jwtAudience == "" {
+			log.Fatalf("Config error: OAuth needs an audience, set --oauth-resource or --jwt-audience")
+		}
+		if jwksURL == "" {
+			log.Fatalf("Config error: authorization server publishes no jwks_uri, set --jwks-url")
+		}
+	} else if oauthResource != "" || oauthClientID != "" {
+		log.Fatalf("Config error: --oauth-resource and --oauth-client-id require --oauth-issuer")
+	}
+	var jwtAuth *jwtValidator
+	if jwksURL != "" {
+		// Without an audience, any token the identity provider issued for
+		// another application would be accepted.
+		if jwtAudience == "" {
+			log.Fatalf("Config error: --jwks-url requires --jwt-audience")
+		}
+		jwtAuth = newJWTValidator(jwksURL, jwtIssuer, jwtAudience)
+	} else if jwtIssuer != "" || jwtAudience != "" {
+		log.Fatalf("Config error: --jwt-issuer and --jwt-audience require --jwks-url")
+	}
+	auth, err := newAuthenticator(apiKeys, apiKeysFile, basicAuth, basicAuthFile, jwtAuth)
+	if err != nil {
+		log.Fatalf("Config error: %v", err)
+	}
+	if auth != nil {
+		auth.oauth = oauth
+	}
+	var signer *requestSigner
+	if hmacSecret != "" {
+		signer = newRequestSigner(hmacSecret)
+	}
+	if rateLimitKey != "session" && rateLimitKey != "ip" {
+		log.Fatalf("Config error: --rate-limit-key must be session or ip")
+	}
+
+	toolCalls := newToolCallTracker()
+	registry := newSessionRegistry()
+	hooks := &server.Hooks{}
+	registry.addHooks(hooks)
+	var router *sessionRouter
+	if sessionStoreURL != "" {
+		if advertiseURL == "" {
+			log.Fatalf("Config error: --session-store requires --advertise-url")
+		}
+		store, err := newRedisSessionStore(sessionStoreURL)
+		if err != nil {
+			log.Fatalf("Config error: %v", err)
+		}
+		router = newSessionRouter(store, strings.TrimSuffix(advertiseURL, "/"), registry)
+		router.addHooks(hooks)
+	}
+	var serverOpts []server.ServerOption
+	var redactor *mcpserver.Redactor
+	if redactRules != "" {
+		var err error
+		if redactor, err = loadRedactor(redactRules); err != nil {
+			log.Fatalf("Config error: %v", err)
+		}
+		mcpserver.SetLogRedactor(redactor)
+	}
+	// Tool middlewares run in the order they are added: the audit log sees
+	// every call, refused ones included, and results are masked before they
+	// are truncated so no partial secret survives.
+	var ring *keyring
+	if encryptionKeys != "" {
+		var err error
+		if ring, err = parseKeyring(encryptionKeys); err != nil {
+			log.Fatalf("Config error: %v", err)
+		}
+	}
+	if auditLogPath != "" {
+		audit, err := newAuditLog(auditLogPath, redactor, ring)
+		if err != nil {
+			log.Fatalf("Config error: %v", err)
+		}
+		serverOpts = append(serverOpts, server.WithToolHandlerMiddleware(audit.middleware))
+	}
+	if toolPolicyFile != "" {
+		policy, err := loadToolPolicy(toolPolicyFile)
+		if err != nil {
+			log.Fatalf("Config error: %v", err)
+		}
+		serverOpts = append(serverOpts, server.WithToolFilter(policy.filter), server.WithToolHandlerMiddleware(policy.middleware))
+	}
+	if toolQuota != "" {
+		quotas, err := parseToolQuotas(toolQuota)
+		if err != nil {
+			log.Fatalf("Config error: %v", err)
+		}
+		serverOpts = append(serverOpts, server.WithToolHandlerMiddleware(quotas.middleware))
+	}
+	var approvals *approvalGate
+	if approvalTools != "" {
+		// Without a token, the client whose call is held could approve it.
+		if adminToken == "" {
+			log.Fatalf("Config error: --approval-tools requires --admin-token")
+		}
+		approvals = newApprovalGate(approvalTools, approvalTimeout, adminToken, redactor)
+		serverOpts = append(serverOpts, server.WithToolHandlerMiddleware(approvals.middleware))
+	}
+	if maxResultBytes > 0 {
+		serverOpts = append(serverOpts, server.WithToolHandlerMiddleware(resultLimitMiddleware(maxResultBytes)))
+	}
+	if redactor != nil {
+		serverOpts = append(serverOpts, server.WithToolHandlerMiddleware(redactMiddleware(redactor)))
+	}
+	if toolTimeout > 0 {
+		serverOpts = append(serverOpts, server.WithToolHandlerMiddleware(timeoutMiddleware(toolTimeout)))
+	}
+	serverOpts = append(serverOpts, server.WithToolHandlerMiddleware(toolCalls.middleware))
+	mcpServer := mcpserver.NewMCPServer(hooks, serverOpts...)
+	if err := selectTools(mcpServer, enableTools, disableTools); err != nil {
+		log.Fatalf("Config error: %v", err)
+	}
+
+	if notificationRoutes != "" {
+		routes, err := loadNotificationRoutes(notificationRoutes)
+		if err != nil {
+			log.Fatalf("Config error: %v", err)
+		}
+		addNotificationRoutes(mcpServer, routes)
+	}
+
+	transports, err := parseTransports(transport)
+	if err != nil {
+		log.Fatalf("Config error: %v", err)
+	}
+	if approvals != nil && !transports["sse"] && !transports["streamable-http"] {
+		log.Fatalf("Config error: --approval-tools needs an HTTP transport to serve /admin/approvals")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	// streamsCtx outlives the signal so streams stay open while running tool
+	// calls are drained.
+	streamsCtx, stopStreams := context.WithCancel(context.Background())
+	defer stopStreams()
+
+	// Every transport shares the same MCPServer instance.
+	errCh := make(chan error, len(transports))
+	// The TLS config is shared by the HTTP and gRPC listeners, so the ACME
+	// certificates are obtained once.
+	var tlsConfig *tls.Config
+	if acmeDomain != "" {
+		tlsConfig = newACMETLSConfig(acmeDomain, acmeCacheDir, acmeEmail)
+	} else if useTLS {
+		cert, err := tls.LoadX509KeyPair(tlsCert, tlsKey)
+		if err != nil {
+			log.Fatalf("Config error: %v", err)
+		}
+		tlsConfig = &tls.Config{Certificates: []tls.Certificate{cert}}
+	}
+	var sessions *sessionTracker
+	if transports["sse"] || transports["streamable-http"] || transports["grpc"] {
+		sessions = newSessionTracker(maxSessions, registry, adminToken)
+	}
+	var httpServer *http.Server
+	var adminServer *http.Server
+	if transports["sse"] || transports["streamable-http"] {
+		var limiter *clientRateLimiter
+		if rateLimit > 0 {
+			limiter = newClientRateLimiter(rateLimit, rateBurst, rateLimitKey, registry)
+		}
+		var mux *http.ServeMux
+		if adminPort != "" {
+			mux = http.NewServeMux()
+			// pprof and the metrics are only meant for the operator, so the
+			// listener stays on loopback unless an address is given, which
+			// then requires the admin token.
+			adminAddr := adminPort
+			if !strings.Contains(adminPort, ":") {
+				adminAddr = net.JoinHostPort("127.0.0.1", adminPort)
+			}
+			adminHost, _, err := net.SplitHostPort(adminAddr)
+			if err != nil {
+				log.Fatalf("Config error: invalid --admin-port: %v", err)
+			}
+			if ip := net.ParseIP(adminHost); (ip == nil || !ip.IsLoopback()) && adminHost != "localhost" && adminToken == "" {
+				log.Fatalf("Config error: --admin-port on a non-loopback address requires --admin-token")
+			}
+			adminServer = &http.Server{Addr: adminAddr, Handler: newAdminMux(sessions, approvals)}
+			adminListener, err := net.Listen("tcp", adminAddr)
+			if err != nil {
+				log.Fatalf("Server error: admin listener: %v", err)
+			}
+			log.Printf("Admin listener bound to %s", adminListener.Addr())
+			go func() {
+				if err := adminServer.Serve(adminListener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+					log.Fatalf("Server error: admin listener: %v", err)
+				}
+			}()
+		} else {
+			mux = newOpsMux(basePath, sessions, approvals, false)
+			if adminToken == "" {
+				log.Printf("Admin sessions and metrics endpoints disabled: set --admin-token or --admin-port to enable them")
+			}
+		}
+		discovery := &discoveryDocument{baseURL: fullBaseURL, trustProxy: trustProxy, endpoints: map[string]string{}}
+		if oauth != nil {
+			oauth.handle(mux, fullBaseURL, basePath, oauthResource)
+			discovery.endpoints["oauth_protected_resource"] = basePath + PROTECTED_RESOURCE_METADATA_PATH
+		}
+		for name := range transports {
+			discovery.transports = append(discovery.transports, name)
+		}
+		sort.Strings(discovery.transports)
+		if transports["sse"] {
+			sseOpts := []server.SSEOption{
+				server.WithBaseURL(fullBaseURL),
+				server.WithStaticBasePath(basePath),
+				server.WithSSEEndpoint(ssePath),
+				server.WithMessageEndpoint(messagePath),
+				server.WithUseFullURLForMessageEndpoint(!trustProxy),
+			}
+			if sseKeepAlive > 0 {
+				sseOpts = append(sseOpts, server.WithKeepAliveInterval(sseKeepAlive))
+			}
+			var idle *idleSessions
+			if sessionIdleTimeout > 0 {
+				idle = newIdleSessions(sessionIdleTimeout)
+				sseOpts = append(sseOpts, server.WithSessionIDGenerator(idle.generateSessionID))
+			}
+			sseServer := server.NewSSEServer(mcpServer, sseOpts...)
+			handleSSE(mux, sseServer, sessions, limiter, idle, router, buffering, auth, signer, trustProxy)
+			discovery.endpoints["sse"] = sseServer.CompleteSsePath()
+			discovery.endpoints["message"] = sseServer.CompleteMessagePath()
+			log.Printf("SSE server listening on %s", fullBaseURL)
+			log.Printf("SSE endpoint: %s%s", fullBaseURL, sseServer.CompleteSsePath())
+			log.Printf("Message endpoint: %s%s", fullBaseURL, sseServer.CompleteMessagePath())
+		}
+		if transports["streamable-http"] {
+			streamableSessions := newStreamableSessions(sessionIdleTimeout)
+			streamableServer := server.NewStreamableHTTPServer(
+				mcpServer,
+				server.WithEndpointPath(basePath+STREAMABLE_HTTP_PATH),
+				server.WithHeartbeatInterval(sseKeepAlive),
+				server.WithSessionIdManager(streamableSessions),
+			)
+			streamableSessions.start(streamableServer, basePath+STREAMABLE_HTTP_PATH)
+			sessions.streamable = streamableSessions
+			mux.Handle(basePath+STREAMABLE_HTTP_PATH, auth.middleware(signer.middleware(router.middleware(sessions.streamableMiddleware(limiter.middleware(streamableServer))))))
+			discovery.endpoints["streamable_http"] = basePath + STREAMABLE_HTTP_PATH
+			log.Printf("Streamable HTTP server listening on %s%s%s", fullBaseURL, basePath, STREAMABLE_HTTP_PATH)
+		}
+		discovery.register(mux, basePath)
+		serverHosts := []string{urlHost(fullBaseURL), urlHost(advertiseURL)}
+		if acmeDomain != "" {
+			serverHosts = append(serverHosts, strings.Split(acmeDomain, ",")...)
+		}
+		middlewares := middlewareRegistry{
+			"origin": newOriginPolicy(allowedOrigins, serverHosts, trustProxy).middleware,
+			"max-bytes": func(next http.Handler) http.Handler {
+				if maxRequestBytes <= 0 {
+					return next
+				}
+				return maxBytesMiddleware(next, maxRequestBytes)
+			},
+			"gzip":             gzipMiddleware,
+			"headers":          func(next http.Handler) http.Handler { return headersMiddleware(next, http.Header(headers)) },
+			"access-log":       accessLogMiddleware,
+			"stream-deadline":  streamDeadlineMiddleware,
+			"request-id":       requestIDMiddleware,
+			"security-headers": securityHeadersMiddleware,
+		}
+		// The default chain, outermost first, holds what the individual
+		// flags enable. The required ones stay even when --middleware leaves
+		// them out.
+		var defaults, required []string
+		if readTimeout > 0 || writeTimeout > 0 {
+			defaults = append(defaults, "stream-deadline")
+			required = append(required, "stream-deadline")
+		}
+		if accessLog {
+			defaults = append(defaults, "access-log")
+		}
+		if len(headers) > 0 {
+			defaults = append(defaults, "headers")
+		}
+		if gzipEnabled {
+			defaults = append(defaults, "gzip")
+		}
+		if maxRequestBytes > 0 {
+			defaults = append(defaults, "max-bytes")
+			required = append(required, "max-bytes")
+		}
+		defaults = append(defaults, "origin")
+		required = append(required, "origin")
+		handler, err := middlewares.buildChain(mux, middlewareOrder, defaults, required)
+		if err != nil {
+			log.Fatalf("Config error: %v", err)
+		}
+		if h2cEnabled && !useTLS {
+			handler = h2c.NewHandler(handler, &http2.Server{})
+		}
+		httpServer = &http.Server{
+			Handler:           handler,
+			ReadHeaderTimeout: readHeaderTimeout,
+			ReadTimeout:       readTimeout,
+			WriteTimeout:      writeTimeout,
+			IdleTimeout:       idleTimeout,
+			BaseContext:       func(net.Listener) context.Context { return streamsCtx },
+			TLSConfig:         tlsConfig,
+		}
+		listener, err := newListener(listen, addr, socketMode)
+		if err != nil {
+			log.Fatalf("Server error: %v", err)
+		}
+		log.Printf("HTTP listener bound to %s", listener.Addr())
+		go func() {
+			var err error
+			if useTLS {
+				err = httpServer.ServeTLS(listener, "", "")
+			} else {
+				err = httpServer.Serve(listener)
+			}
+			if errors.Is(err, http.ErrServerClosed) {
+				err = nil
+			}
+			errCh <- err
+		}()
+	}
+	if transports["grpc"] {
+		grpcAddr := ":" + grpcPort
+		if host, _, err := net.SplitHostPort(addr); err == nil && host != "" {
+			grpcAddr = net.JoinHostPort(host, grpcPort)
+		}
+		grpcListener, err := net.Listen("tcp", grpcAddr)
+		if err != nil {
+			log.Fatalf("Server error: %v", err)
+		}
+		grpcServer := newGRPCServer(mcpServer, auth, sessions, tlsConfig)
+		if tlsConfig != nil {
+			log.Printf("gRPC server listening on %s with TLS", grpcListener.Addr())
+		} else {
+			log.Printf("gRPC server listening on %s", grpcListener.Addr())
+		}
+		// Running tool calls have been drained by the time streams are
+		// stopped, so the remaining sessions can be closed right away.
+		go func() {
+			<-streamsCtx.Done()
+			grpcServer.Stop()
+		}()
+		go func() {
+			errCh <- grpcServer.Serve(grpcListener)
+		}()
+	}
+	if transports["stdio"] {
+		go func() {
+			err := server.NewStdioServer(mcpServer).Listen(streamsCtx, os.Stdin, os.Stdout)
+			if errors.Is(err, context.Canceled) {
+				err = nil
+			}
+			errCh <- err
+		}()
+	}
+
+	// A transport that stops cleanly, such as stdio on EOF, leaves the
+	// others running.
+	done := make(chan struct{})
+	go func() {
+		for range transports {
+			if err := <-errCh; err != nil {
+				log.Fatalf("Server error: %v", err)
+			}
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		log.Printf("Shutting down, waiting up to %s for running tool calls", shutdownTimeout)
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		router.stop()
+		gracefulShutdown(shutdownCtx, sessions, approvals, toolCalls, stopStreams, httpServer, adminServer)
+	}
+}
+
+// parseTransports parses a comma-separated transport list. "both" is
+// shorthand for stdio and sse.
+func parseTransports(value string) (map[string]bool, error) {
+	transports := make(map[string]bool)
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		switch name {
+		case "stdio", "sse", "streamable-http", "grpc":
+			transports[name] = true
+		case "both":
+			transports["stdio"] = true
+			transports["sse"] = true
+		default:
+			return nil, fmt.Errorf("unknown transport %q", name)
+		}
+	}
+	return transports, nil
+}
@@ -0,0 +1,314 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// statusRecorder captures the status code and size of a response while
+// still letting SSE handlers flush.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(p []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	n, err := r.ResponseWriter.Write(p)
+	r.bytes += n
+	return n, err
+}
+
+func (r *statusRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// requestSessionID returns the MCP session a request belongs to, if any: the
+// sessionId query parameter of the SSE message endpoint or the streamable
+// HTTP session header.
+func requestSessionID(r *http.Request, w http.ResponseWriter) string {
+	if id := r.URL.Query().Get("sessionId"); id != "" {
+		return id
+	}
+	if id := r.Header.Get(server.HeaderKeySessionID); id != "" {
+		return id
+	}
+	return w.Header().Get(server.HeaderKeySessionID)
+}
+
+// accessLogMiddleware writes one JSON line per request to stderr once the
+// request completes. For SSE streams that is when the stream closes.
+func accessLogMiddleware(next http.Handler) http.Handler {
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, nil))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(recorder, r)
+
+		remoteIP, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			remoteIP = r.RemoteAddr
+		}
+		attrs := []any{
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.Int("status", recorder.status),
+			slog.Int("bytes", recorder.bytes),
+			slog.Duration("duration", time.Since(start)),
+			slog.String("remote_ip", remoteIP),
+		}
+		if forwardedFor := r.Header.Get("X-Forwarded-For"); forwardedFor != "" {
+			attrs = append(attrs, slog.String("forwarded_for", forwardedFor))
+		}
+		if sessionID := requestSessionID(r, recorder); sessionID != "" {
+			attrs = append(attrs, slog.String("session_id", sessionID))
+		}
+		if requestID := recorder.Header().Get(REQUEST_ID_HEADER); requestID != "" {
+			attrs = append(attrs, slog.String("request_id", requestID))
+		}
+		logger.Info("http request", attrs...)
+	})
+}
+
+// headerFlags collects repeated --header key=value flags.
+type headerFlags http.Header
+
+func (h headerFlags) String() string {
+	var pairs []string
+	for key, values := range h {
+		for _, value := range values {
+			pairs = append(pairs, key+"="+value)
+		}
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (h headerFlags) Set(pair string) error {
+	key, value, found := strings.Cut(pair, "=")
+	if !found || strings.TrimSpace(key) == "" {
+		return fmt.Errorf("expected key=value, got %q", pair)
+	}
+	http.Header(h).Add(strings.TrimSpace(key), value)
+	return nil
+}
+
+// headerWriter applies the configured headers just before the response
+// header is written, so they take precedence over those set by the
+// transport handlers, such as the SSE Cache-Control.
+type headerWriter struct {
+	http.ResponseWriter
+	headers     http.Header
+	wroteHeader bool
+}
+
+func (w *headerWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		for key, values := range w.headers {
+			w.ResponseWriter.Header()[key] = values
+		}
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *headerWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+func (w *headerWriter) Flush() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (w *headerWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// headersMiddleware adds the --header values to every response.
+func headersMiddleware(next http.Handler, headers http.Header) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(&headerWriter{ResponseWriter: w, headers: headers}, r)
+	})
+}
+
+// gzipWriter compresses JSON responses once their headers are known. Event
+// streams and every other content type are passed through untouched.
+type gzipWriter struct {
+	http.ResponseWriter
+	gz          *gzip.Writer
+	wroteHeader bool
+}
+
+func (w *gzipWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		header := w.ResponseWriter.Header()
+		if strings.HasPrefix(header.Get("Content-Type"), "application/json") &&
+			header.Get("Content-Encoding") == "" &&
+			status != http.StatusNoContent && status != http.StatusNotModified {
+			header.Set("Content-Encoding", "gzip")
+			header.Add("Vary", "Accept-Encoding")
+			header.Del("Content-Length")
+			w.gz = gzip.NewWriter(w.ResponseWriter)
+		}
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *gzipWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.gz != nil {
+		return w.gz.Write(p)
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+func (w *gzipWriter) Flush() {
+	if w.gz != nil {
+		w.gz.Flush()
+	}
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (w *gzipWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// gzipMiddleware compresses JSON-RPC responses for clients that accept gzip.
+func gzipMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+			next.ServeHTTP(w, r)
+			return
+		}
+		gw := &gzipWriter{ResponseWriter: w}
+		defer func() {
+			if gw.gz != nil {
+				gw.gz.Close()
+			}
+		}()
+		next.ServeHTTP(gw, r)
+	})
+}
+
+// writeJSONRPCError answers a request the MCP server never saw with a
+// JSON-RPC error, so clients report it like any other protocol error.
+func writeJSONRPCError(w http.ResponseWriter, status int, code int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(mcp.JSONRPCError{
+		JSONRPC: mcp.JSONRPC_VERSION,
+		Error:   mcp.NewJSONRPCErrorDetails(code, message, nil),
+	})
+}
+
+// maxBytesMiddleware rejects request bodies larger than limit bytes with 413
+// and a JSON-RPC error. The body is read up front because the transports
+// turn read errors into generic parse errors.
+func maxBytesMiddleware(next http.Handler, limit int64) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Body == nil || r.Body == http.NoBody {
+			next.ServeHTTP(w, r)
+			return
+		}
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, limit))
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				writeJSONRPCError(w, http.StatusRequestEntityTooLarge, mcp.INVALID_REQUEST,
+					fmt.Sprintf("request body exceeds %d bytes", limit))
+				return
+			}
+			http.Error(w, "failed to read request body", http.StatusBadRequest)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		next.ServeHTTP(w, r)
+	})
+}
+
+// streamDeadlineWriter lifts the server's read and write deadlines once a
+// response turns out to be an event stream, so --read-timeout and
+// --write-timeout only bound regular request/response exchanges.
+type streamDeadlineWriter struct {
+	http.ResponseWriter
+	wroteHeader bool
+}
+
+func (w *streamDeadlineWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		if strings.HasPrefix(w.Header().Get("Content-Type"), "text/event-stream") {
+			rc := http.NewResponseController(w.ResponseWriter)
+			rc.SetReadDeadline(time.Time{})
+			rc.SetWriteDeadline(time.Time{})
+		}
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *streamDeadlineWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+func (w *streamDeadlineWriter) Flush() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (w *streamDeadlineWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func streamDeadlineMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(&streamDeadlineWriter{ResponseWriter: w}, r)
+	})
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+type NotificationAction string
+
+const (
+	ACTION_LOG     NotificationAction = "log"
+	ACTION_WEBHOOK NotificationAction = "webhook"
+	ACTION_TOOL    NotificationAction = "tool"
+)
+
+// NotificationRoute maps an incoming client notification method to a
+// server-side action.
+type NotificationRoute struct {
+	Method    string                 `json:"method"`
+	Action    NotificationAction     `json:"action"`
+	URL       string                 `json:"url,omitempty"`
+	Tool      string                 `json:"tool,omitempty"`
+	Arguments map[string]interface{} `json:"arguments,omitempty"`
+}
+
+type notificationRoutesConfig struct {
+	Routes []NotificationRoute `json:"routes"`
+}
+
+var webhookClient = &http.Client{Timeout: 10 * time.Second}
+
+// toolCallID numbers the internal tools/call requests issued by tool routes.
+var toolCallID atomic.Int64
+
+// loadNotificationRoutes reads and validates the routes defined in a JSON file.
+func loadNotificationRoutes(path string) ([]NotificationRoute, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read notification routes: %w", err)
+	}
+	var config notificationRoutesConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse notification routes: %w", err)
+	}
+	for i, route := range config.Routes {
+		if route.Method == "" {
+			return nil, fmt.Errorf("notification route %d: missing method", i)
+		}
+		switch route.Action {
+		case ACTION_LOG:
+		case ACTION_WEBHOOK:
+			if route.URL == "" {
+				return nil, fmt.Errorf("notification route %d: webhook action requires url", i)
+			}
+		case ACTION_TOOL:
+			if route.Tool == "" {
+				return nil, fmt.Errorf("notification route %d: tool action requires tool", i)
+			}
+		default:
+			return nil, fmt.Errorf("notification route %d: unknown action %q", i, route.Action)
+		}
+	}
+	return config.Routes, nil
+}
+
+// addNotificationRoutes registers a notification handler for every route.
+// Routes sharing a method run in the order they were declared.
+func addNotificationRoutes(mcpServer *server.MCPServer, routes []NotificationRoute) {
+	byMethod := make(map[string][]NotificationRoute)
+	for _, route := range routes {
+		byMethod[route.Method] = append(byMethod[route.Method], route)
+	}
+	for method, methodRoutes := range byMethod {
+		mcpServer.AddNotificationHandler(method, func(
+			ctx context.Context,
+			notification mcp.JSONRPCNotification,
+		) {
+			for _, route := range methodRoutes {
+				if err := runNotificationRoute(ctx, mcpServer, route, notification); err != nil {
+					log.Printf("Notification route %s -> %s failed: %v", route.Method, route.Action, err)
+				}
+			}
+		})
+	}
+}
+
+func runNotificationRoute(
+	ctx context.Context,
+	mcpServer *server.MCPServer,
+	route NotificationRoute,
+	notification mcp.JSONRPCNotification,
+) error {
+	switch route.Action {
+	case ACTION_LOG:
+		params, _ := json.Marshal(notification.Params)
+		log.Printf("Received notification: %s %s", notification.Method, params)
+		return nil
+	case ACTION_WEBHOOK:
+		body, err := json.Marshal(notification)
+		if err != nil {
+			return fmt.Errorf("failed to marshal notification: %w", err)
+		}
+		// Webhooks must not block the session's message loop.
+		go func() {
+			resp, err := webhookClient.Post(route.URL, "application/json", bytes.NewReader(body))
+			if err != nil {
+				log.Printf("Notification webhook %s failed: %v", route.URL, err)
+				return
+			}
+			resp.Body.Close()
+			if resp.StatusCode >= 300 {
+				log.Printf("Notification webhook %s returned %s", route.URL, resp.Status)
+			}
+		}()
+		return nil
+	case ACTION_TOOL:
+		// Notification params fill in any argument not set by the route.
+		arguments := make(map[string]interface{})
+		for k, v := range notification.Params.AdditionalFields {
+			arguments[k] = v
+		}
+		for k, v := range route.Arguments {
+			arguments[k] = v
+		}
+		request, err := json.Marshal(map[string]interface{}{
+			"jsonrpc": mcp.JSONRPC_VERSION,
+			"id":      fmt.Sprintf("notification-route-%d", toolCallID.Add(1)),
+			"method":  "tools/call",
+			"params": map[string]interface{}{
+				"name":      route.Tool,
+				"arguments": arguments,
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to marshal tool call: %w", err)
+		}
+		response := mcpServer.HandleMessage(ctx, request)
+		if rpcErr, ok := response.(mcp.JSONRPCError); ok {
+			return fmt.Errorf("tool %s: %s", route.Tool, rpcErr.Error.Message)
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown action %q", route.Action)
+}
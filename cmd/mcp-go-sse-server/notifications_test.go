@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func writeTestRoutes(t *testing.T, routes string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "routes.json")
+	if err := os.WriteFile(path, []byte(routes), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadNotificationRoutes(t *testing.T) {
+	tests := []struct {
+		name    string
+		routes  string
+		wantErr bool
+	}{
+		{"valid", `{"routes": [
+			{"method": "notifications/a", "action": "log"},
+			{"method": "notifications/a", "action": "webhook", "url": "http://hooks.example.com"},
+			{"method": "notifications/b", "action": "tool", "tool": "echo"}
+		]}`, false},
+		{"missing method", `{"routes": [{"action": "log"}]}`, true},
+		{"webhook without url", `{"routes": [{"method": "notifications/a", "action": "webhook"}]}`, true},
+		{"tool without tool", `{"routes": [{"method": "notifications/a", "action": "tool"}]}`, true},
+		{"unknown action", `{"routes": [{"method": "notifications/a", "action": "email"}]}`, true},
+		{"invalid JSON", `{"routes": [`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loadNotificationRoutes(writeTestRoutes(t, tt.routes))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("loadNotificationRoutes() error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// sendTestNotification delivers a client notification to mcpServer.
+func sendTestNotification(t *testing.T, mcpServer *server.MCPServer, method string, params map[string]any) {
+	t.Helper()
+	message, err := json.Marshal(map[string]any{"jsonrpc": mcp.JSONRPC_VERSION, "method": method, "params": params})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mcpServer.HandleMessage(context.Background(), message)
+}
+
+func TestNotificationRouteWebhook(t *testing.T) {
+	bodies := make(chan []byte, 1)
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies <- body
+	}))
+	defer hook.Close()
+
+	mcpServer := server.NewMCPServer("test", "1.0.0")
+	addNotificationRoutes(mcpServer, []NotificationRoute{{Method: "notifications/progress", Action: ACTION_WEBHOOK, URL: hook.URL}})
+	sendTestNotification(t, mcpServer, "notifications/progress", map[string]any{"progress": 3})
+
+	select {
+	case body := <-bodies:
+		var notification mcp.JSONRPCNotification
+		if err := json.Unmarshal(body, &notification); err != nil {
+			t.Fatal(err)
+		}
+		if notification.Method != "notifications/progress" || notification.Params.AdditionalFields["progress"] != float64(3) {
+			t.Errorf("webhook received %s", body)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("webhook was not called")
+	}
+}
+
+func TestNotificationRouteTool(t *testing.T) {
+	calls := make(chan map[string]any, 2)
+	mcpServer := server.NewMCPServer("test", "1.0.0", server.WithToolCapabilities(false))
+	mcpServer.AddTool(mcp.NewTool("record"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		calls <- request.GetArguments()
+		return mcp.NewToolResultText("ok"), nil
+	})
+	addNotificationRoutes(mcpServer, []NotificationRoute{
+		{Method: "notifications/changed", Action: ACTION_LOG},
+		{Method: "notifications/changed", Action: ACTION_TOOL, Tool: "record", Arguments: map[string]any{"source": "route", "fixed": true}},
+		{Method: "notifications/other", Action: ACTION_TOOL, Tool: "record"},
+	})
+
+	sendTestNotification(t, mcpServer, "notifications/changed", map[string]any{"source": "client", "table": "users"})
+	select {
+	case arguments := <-calls:
+		// Route arguments take precedence over notification params.
+		want := map[string]any{"source": "route", "fixed": true, "table": "users"}
+		if len(arguments) != len(want) {
+			t.Fatalf("tool arguments = %v, want %v", arguments, want)
+		}
+		for k, v := range want {
+			if arguments[k] != v {
+				t.Errorf("tool argument %s = %v, want %v", k, arguments[k], v)
+			}
+		}
+	default:
+		t.Fatal("tool route did not call the tool")
+	}
+
+	sendTestNotification(t, mcpServer, "notifications/unrouted", nil)
+	if len(calls) != 0 {
+		t.Error("a notification without a route called the tool")
+	}
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Well-known paths of the OAuth metadata documents, served under --base-path.
+const (
+	PROTECTED_RESOURCE_METADATA_PATH   = "/.well-known/oauth-protected-resource"
+	AUTHORIZATION_SERVER_METADATA_PATH = "/.well-known/oauth-authorization-server"
+	CLIENT_REGISTRATION_PATH           = "/register"
+)
+
+// oauthProvider makes this server an OAuth 2.0 protected resource as described
+// by the MCP authorization spec. Tokens are issued by an external
+// authorization server and validated as JWTs; this server only publishes the
+// metadata clients need to find that authorization server, and optionally a
+// registration endpoint handing out a pre-registered client ID.
+type oauthProvider struct {
+	issuer   string
+	clientID string
+	// metadata is the authorization server metadata fetched at startup.
+	metadata map[string]any
+
+	resource            string
+	resourceMetadataURL string
+	registrationURL     string
+}
+
+// newOAuthProvider fetches the metadata of the authorization server issuer,
+// trying the RFC 8414 location first and OpenID Connect discovery second.
+func newOAuthProvider(issuer string, clientID string) (*oauthProvider, error) {
+	issuerURL, err := url.Parse(issuer)
+	if err != nil || issuerURL.Scheme == "" || issuerURL.Host == "" {
+		return nil, fmt.Errorf("invalid --oauth-issuer %q", issuer)
+	}
+	issuerPath := strings.TrimSuffix(issuerURL.Path, "/")
+	candidates := []string{
+		issuerURL.Scheme + "://" + issuerURL.Host + AUTHORIZATION_SERVER_METADATA_PATH + issuerPath,
+		strings.TrimSuffix(issuer, "/") + "/.well-known/openid-configuration",
+	}
+	client := &http.Client{Timeout: 10 * time.Second}
+	for _, candidate := range candidates {
+		metadata, err := fetchJSON(client, candidate)
+		if err != nil {
+			log.Printf("OAuth metadata not found at %s: %v", candidate, err)
+			continue
+		}
+		return &oauthProvider{issuer: issuer, clientID: clientID, metadata: metadata}, nil
+	}
+	return nil, fmt.Errorf("failed to fetch authorization server metadata for %s", issuer)
+}
+
+func fetchJSON(client *http.Client, url string) (map[string]any, error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	var document map[string]any
+	if err := json.NewDecoder(resp.Body).Decode(&document); err != nil {
+		return nil, err
+	}
+	return document, nil
+}
+
+// jwksURI returns the JWKS URL advertised by the authorization server.
+func (p *oauthProvider) jwksURI() string {
+	uri, _ := p.metadata["jwks_uri"].(string)
+	return uri
+}
+
+// handle registers the metadata endpoints, and the registration endpoint
+// when a client ID is configured. resource identifies this server to the
+// authorization server.
+func (p *oauthProvider) handle(mux *http.ServeMux, publicURL string, basePath string, resource string) {
+	p.resource = resource
+	p.resourceMetadataURL = publicURL + basePath + PROTECTED_RESOURCE_METADATA_PATH
+	mux.HandleFunc(basePath+PROTECTED_RESOURCE_METADATA_PATH, p.handleProtectedResource)
+	mux.HandleFunc(basePath+AUTHORIZATION_SERVER_METADATA_PATH, p.handleAuthorizationServer)
+	if p.clientID != "" {
+		p.registrationURL = publicURL + basePath + CLIENT_REGISTRATION_PATH
+		mux.HandleFunc(basePath+CLIENT_REGISTRATION_PATH, p.handleRegister)
+	}
+	log.Printf("OAuth protected resource metadata: %s", p.resourceMetadataURL)
+}
+
+// handleProtectedResource serves the RFC 9728 protected resource metadata.
+func (p *oauthProvider) handleProtectedResource(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"resource":                 p.resource,
+		"authorization_servers":    []string{p.issuer},
+		"bearer_methods_supported": []string{"header"},
+	})
+}
+
+// handleAuthorizationServer mirrors the authorization server metadata for
+// clients that look it up on the MCP server itself, as in earlier revisions
+// of the MCP authorization spec.
+func (p *oauthProvider) handleAuthorizationServer(w http.ResponseWriter, r *http.Request) {
+	metadata := make(map[string]any, len(p.metadata)+1)
+	for k, v := range p.metadata {
+		metadata[k] = v
+	}
+	if p.registrationURL != "" {
+		metadata["registration_endpoint"] = p.registrationURL
+	}
+	writeJSON(w, http.StatusOK, metadata)
+}
+
+// handleRegister implements RFC 7591 dynamic client registration for
+// authorization servers without it, by returning the same pre-registered
+// public client to every caller. The client's redirect URIs must be allowed
+// for that client at the authorization server.
+func (p *oauthProvider) handleRegister(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var request map[string]any
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]any{
+			"error":             "invalid_client_metadata",
+			"error_description": "request body must be a JSON object",
+		})
+		return
+	}
+	response := map[string]any{}
+	for _, field := range []string{"redirect_uris", "client_name", "grant_types", "response_types", "scope"} {
+		if value, ok := request[field]; ok {
+			response[field] = value
+		}
+	}
+	response["client_id"] = p.clientID
+	response["client_id_issued_at"] = time.Now().Unix()
+	response["token_endpoint_auth_method"] = "none"
+	writeJSON(w, http.StatusCreated, response)
+}
+
+// wwwAuthenticate returns the challenge sent with 401 responses, pointing
+// OAuth clients at the protected resource metadata. A nil provider returns
+// a plain bearer challenge.
+func (p *oauthProvider) wwwAuthenticate() string {
+	if p == nil || p.resourceMetadataURL == "" {
+		return `Bearer realm="mcp"`
+	}
+	return fmt.Sprintf(`Bearer realm="mcp", resource_metadata=%q`, p.resourceMetadataURL)
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
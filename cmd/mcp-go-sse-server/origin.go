@@ -0,0 +1,108 @@
+package main
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// originPolicy rejects browser requests from other origins, which a page
+// could otherwise send to a server on localhost or, through DNS rebinding,
+// under its own host name. Both the Host and Origin headers are checked
+// against a fixed set of host names, since a rebinding page controls both.
+// Requests without an Origin header, such as those of native MCP clients,
+// only need an allowed Host.
+type originPolicy struct {
+	origins    map[string]bool
+	hosts      map[string]bool
+	any        bool
+	trustProxy bool
+}
+
+// newOriginPolicy parses a comma-separated list of origins such as
+// https://app.example.com. "*" allows every origin and host. hosts are the
+// names the server is reached at, such as the --baseurl host; localhost,
+// IP addresses and the hosts of the listed origins are always allowed.
+func newOriginPolicy(origins string, hosts []string, trustProxy bool) *originPolicy {
+	p := &originPolicy{origins: map[string]bool{}, hosts: map[string]bool{"localhost": true}, trustProxy: trustProxy}
+	for _, host := range hosts {
+		if host = strings.TrimSpace(host); host != "" {
+			p.hosts[strings.ToLower(host)] = true
+		}
+	}
+	for _, origin := range strings.Split(origins, ",") {
+		origin = strings.TrimSuffix(strings.TrimSpace(origin), "/")
+		if origin == "*" {
+			p.any = true
+		} else if origin != "" {
+			p.origins[strings.ToLower(origin)] = true
+			if parsed, err := url.Parse(origin); err == nil && parsed.Hostname() != "" {
+				p.hosts[strings.ToLower(parsed.Hostname())] = true
+			}
+		}
+	}
+	return p
+}
+
+// urlHost returns the host name of rawURL, or "" if it has none.
+func urlHost(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return parsed.Hostname()
+}
+
+// allowsHost reports whether the request names an allowed host. A page can
+// only rebind a domain name, so IP addresses are always allowed, which keeps
+// health probes and direct access by address working. Behind a proxy, the
+// last X-Forwarded-Host entry is checked, since the proxy appends it after
+// any the client sent.
+func (p *originPolicy) allowsHost(r *http.Request) bool {
+	host := r.Host
+	if p.trustProxy {
+		if forwarded := lastHeaderValue(r, "X-Forwarded-Host"); forwarded != "" {
+			host = forwarded
+		}
+	}
+	name := (&url.URL{Host: host}).Hostname()
+	if p.any || name == "" || net.ParseIP(name) != nil {
+		return true
+	}
+	return p.hosts[strings.ToLower(name)]
+}
+
+// allowsOrigin reports whether the request has no Origin header, or one that
+// is listed or names an allowed host. The request's own Host is not trusted.
+func (p *originPolicy) allowsOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" || p.any || p.origins[strings.ToLower(origin)] {
+		return true
+	}
+	parsed, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	name := parsed.Hostname()
+	if ip := net.ParseIP(name); ip != nil {
+		return ip.IsLoopback()
+	}
+	return p.hosts[strings.ToLower(name)]
+}
+
+// middleware answers requests for other hosts or from disallowed origins
+// with 403.
+func (p *originPolicy) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !p.allowsHost(r) {
+			http.Error(w, "Forbidden: host not allowed", http.StatusForbidden)
+			return
+		}
+		if !p.allowsOrigin(r) {
+			http.Error(w, "Forbidden: origin not allowed", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestOriginPolicyMiddleware(t *testing.T) {
+	policy := newOriginPolicy("https://app.example.com,http://localhost:6274", []string{"mcp.example.com"}, false)
+	proxied := newOriginPolicy("", []string{"mcp.example.com"}, true)
+	open := newOriginPolicy("*", nil, false)
+
+	tests := []struct {
+		name       string
+		policy     *originPolicy
+		host       string
+		header     http.Header
+		wantStatus int
+	}{
+		{"allowed host", policy, "mcp.example.com", nil, http.StatusOK},
+		{"allowed host with port", policy, "MCP.example.com:8080", nil, http.StatusOK},
+		{"localhost", policy, "localhost:3000", nil, http.StatusOK},
+		{"IP address", policy, "10.0.0.7:3000", nil, http.StatusOK},
+		{"IPv6 address", policy, "[::1]:3000", nil, http.StatusOK},
+		{"host of an allowed origin", policy, "app.example.com", nil, http.StatusOK},
+		{"rebound host", policy, "evil.example.com", nil, http.StatusForbidden},
+		{"same origin", policy, "mcp.example.com", http.Header{"Origin": {"https://mcp.example.com"}}, http.StatusOK},
+		{"listed origin", policy, "mcp.example.com", http.Header{"Origin": {"https://app.example.com"}}, http.StatusOK},
+		{"listed origin with another port", policy, "mcp.example.com", http.Header{"Origin": {"https://app.example.com:8443"}}, http.StatusOK},
+		{"loopback origin", policy, "localhost", http.Header{"Origin": {"http://127.0.0.1:5173"}}, http.StatusOK},
+		{"other IP origin", policy, "localhost", http.Header{"Origin": {"http://192.168.1.20"}}, http.StatusForbidden},
+		{"other origin", policy, "mcp.example.com", http.Header{"Origin": {"https://evil.example.com"}}, http.StatusForbidden},
+		{"null origin", policy, "mcp.example.com", http.Header{"Origin": {"null"}}, http.StatusForbidden},
+		{"forwarded host ignored without trust", policy, "evil.example.com", http.Header{"X-Forwarded-Host": {"mcp.example.com"}}, http.StatusForbidden},
+		{"forwarded host", proxied, "backend:3000", http.Header{"X-Forwarded-Host": {"mcp.example.com"}}, http.StatusOK},
+		{"forwarded host of another site", proxied, "backend:3000", http.Header{"X-Forwarded-Host": {"evil.example.com"}}, http.StatusForbidden},
+		{"client-supplied forwarded host", proxied, "backend:3000", http.Header{"X-Forwarded-Host": {"mcp.example.com, evil.example.com"}}, http.StatusForbidden},
+		{"forwarded host appended by the proxy", proxied, "backend:3000", http.Header{"X-Forwarded-Host": {"evil.example.com, mcp.example.com"}}, http.StatusOK},
+		{"forwarded host on separate lines", proxied, "backend:3000", http.Header{"X-Forwarded-Host": {"mcp.example.com", "evil.example.com"}}, http.StatusForbidden},
+		{"any origin", open, "evil.example.com", http.Header{"Origin": {"https://evil.example.com"}}, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := tt.policy.middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+			r := httptest.NewRequest(http.MethodPost, "/message", nil)
+			r.Host = tt.host
+			for key, values := range tt.header {
+				r.Header[key] = values
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+)
+
+var endpointEventPrefix = []byte("event: endpoint\ndata: /")
+
+// forwardedBaseURL derives the public base URL of a request from the
+// X-Forwarded-Proto and X-Forwarded-Host headers set by a reverse proxy,
+// falling back to the request itself.
+func forwardedBaseURL(r *http.Request) string {
+	proto := "http"
+	if r.TLS != nil {
+		proto = "https"
+	}
+	if forwarded := firstHeaderValue(r, "X-Forwarded-Proto"); forwarded == "http" || forwarded == "https" {
+		proto = forwarded
+	}
+	host := r.Host
+	if forwarded := firstHeaderValue(r, "X-Forwarded-Host"); forwarded != "" && !strings.ContainsAny(forwarded, "/\\ ") {
+		host = forwarded
+	}
+	return proto + "://" + host
+}
+
+// firstHeaderValue returns the first entry of a comma-separated header, which
+// is the one set by the proxy closest to the client.
+func firstHeaderValue(r *http.Request, key string) string {
+	value, _, _ := strings.Cut(r.Header.Get(key), ",")
+	return strings.TrimSpace(value)
+}
+
+// lastHeaderValue returns the last entry of a comma-separated header, which
+// is the one appended by the proxy in front of the server. Unlike the first
+// entry, the client cannot choose it.
+func lastHeaderValue(r *http.Request, key string) string {
+	values := r.Header.Values(key)
+	if len(values) == 0 {
+		return ""
+	}
+	value := values[len(values)-1]
+	return strings.TrimSpace(value[strings.LastIndex(value, ",")+1:])
+}
+
+// forwardedEndpointWriter prefixes the relative message endpoint announced in
+// the SSE endpoint event with the request's forwarded base URL.
+type forwardedEndpointWriter struct {
+	http.ResponseWriter
+	baseURL   string
+	rewritten bool
+}
+
+func (w *forwardedEndpointWriter) Write(p []byte) (int, error) {
+	if w.rewritten || !bytes.HasPrefix(p, endpointEventPrefix) {
+		return w.ResponseWriter.Write(p)
+	}
+	w.rewritten = true
+	prefixLen := len(endpointEventPrefix) - 1
+	rewritten := make([]byte, 0, len(p)+len(w.baseURL))
+	rewritten = append(rewritten, p[:prefixLen]...)
+	rewritten = append(rewritten, w.baseURL...)
+	rewritten = append(rewritten, p[prefixLen:]...)
+	if _, err := w.ResponseWriter.Write(rewritten); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (w *forwardedEndpointWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (w *forwardedEndpointWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// trustProxyMiddleware wraps the SSE endpoint of a server configured to
+// announce a relative message endpoint, so that each client is given a URL
+// built from its own X-Forwarded-* headers.
+func trustProxyMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(&forwardedEndpointWriter{ResponseWriter: w, baseURL: forwardedBaseURL(r)}, r)
+	})
+}
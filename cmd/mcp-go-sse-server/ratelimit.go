@@ -0,0 +1,127 @@
+package main
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/server"
+	"golang.org/x/time/rate"
+)
+
+// RATE_LIMITED is the JSON-RPC error code, from the implementation-defined
+// server error range, returned when a client is rate limited.
+const RATE_LIMITED = -32029
+
+// rateLimitIdleTTL is how long an unused client bucket is kept at least.
+const rateLimitIdleTTL = 10 * time.Minute
+
+// rateLimitSweepInterval is how often buckets of ended sessions and idle
+// clients are dropped.
+const rateLimitSweepInterval = time.Minute
+
+type clientLimiter struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// clientRateLimiter applies a token bucket per client, keyed by MCP session
+// ID or by remote IP. Only sessions registered with this process count as
+// clients of their own, so inventing session IDs does not get fresh buckets.
+// Without a session registry, callers supply their own keys to allow.
+type clientRateLimiter struct {
+	limit    rate.Limit
+	burst    int
+	byIP     bool
+	idleTTL  time.Duration
+	sessions *sessionRegistry
+	mu       sync.Mutex
+	clients  map[string]*clientLimiter
+}
+
+// newClientRateLimiter keeps unused buckets until they have refilled, and at
+// least rateLimitIdleTTL, so dropping one never hands a client extra tokens.
+func newClientRateLimiter(perSecond float64, burst int, key string, sessions *sessionRegistry) *clientRateLimiter {
+	refill := time.Duration(float64(burst) / perSecond * float64(time.Second))
+	l := &clientRateLimiter{
+		limit:    rate.Limit(perSecond),
+		burst:    burst,
+		byIP:     key == "ip",
+		idleTTL:  max(rateLimitIdleTTL, refill),
+		sessions: sessions,
+		clients:  make(map[string]*clientLimiter),
+	}
+	go l.sweep()
+	return l
+}
+
+// key identifies the client of r. Requests naming a session unknown to this
+// process are counted against their remote IP.
+func (l *clientRateLimiter) key(r *http.Request) string {
+	if !l.byIP {
+		id := r.URL.Query().Get("sessionId")
+		if id == "" {
+			id = r.Header.Get(server.HeaderKeySessionID)
+		}
+		if id != "" && l.sessions.has(id) {
+			return "session:" + id
+		}
+	}
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+	return "ip:" + ip
+}
+
+func (l *clientRateLimiter) allow(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	client, ok := l.clients[key]
+	if !ok {
+		client = &clientLimiter{limiter: rate.NewLimiter(l.limit, l.burst)}
+		l.clients[key] = client
+	}
+	client.lastSeen = time.Now()
+	return client.limiter.Allow()
+}
+
+// sweep drops the buckets of clients that have gone quiet, and of sessions
+// that have ended, whose requests now count against their IP, so the map does
+// not grow with every session ever seen.
+func (l *clientRateLimiter) sweep() {
+	for range time.Tick(rateLimitSweepInterval) {
+		l.evict()
+	}
+}
+
+func (l *clientRateLimiter) evict() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for key, client := range l.clients {
+		id, isSession := strings.CutPrefix(key, "session:")
+		if time.Since(client.lastSeen) > l.idleTTL || (isSession && l.sessions != nil && !l.sessions.has(id)) {
+			delete(l.clients, key)
+		}
+	}
+}
+
+// middleware limits POSTed JSON-RPC messages and answers 429 with a JSON-RPC
+// error once a client runs out of tokens. A nil limiter lets everything
+// through.
+func (l *clientRateLimiter) middleware(next http.Handler) http.Handler {
+	if l == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || l.allow(l.key(r)) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Set("Retry-After", strconv.Itoa(max(1, int(1/float64(l.limit)))))
+		writeJSONRPCError(w, http.StatusTooManyRequests, RATE_LIMITED, "rate limit exceeded")
+	})
+}
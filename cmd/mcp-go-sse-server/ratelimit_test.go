@@ -0,0 +1,79 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func TestClientRateLimiterKey(t *testing.T) {
+	registry := newSessionRegistry()
+	registry.sessions["known"] = &registeredSession{}
+	request := func(target string, sessionHeader string) *http.Request {
+		r := httptest.NewRequest(http.MethodPost, target, nil)
+		r.RemoteAddr = "192.0.2.1:4242"
+		if sessionHeader != "" {
+			r.Header.Set(server.HeaderKeySessionID, sessionHeader)
+		}
+		return r
+	}
+
+	tests := []struct {
+		name    string
+		keyBy   string
+		request *http.Request
+		wantKey string
+	}{
+		{"known SSE session", "session", request("/message?sessionId=known", ""), "session:known"},
+		{"known streamable session", "session", request("/mcp", "known"), "session:known"},
+		{"unknown session", "session", request("/message?sessionId=invented", ""), "ip:192.0.2.1"},
+		{"unknown streamable session", "session", request("/mcp", "invented"), "ip:192.0.2.1"},
+		{"no session", "session", request("/mcp", ""), "ip:192.0.2.1"},
+		{"by IP", "ip", request("/message?sessionId=known", ""), "ip:192.0.2.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := newClientRateLimiter(1, 1, tt.keyBy, registry)
+			if key := limiter.key(tt.request); key != tt.wantKey {
+				t.Errorf("key() = %q, want %q", key, tt.wantKey)
+			}
+		})
+	}
+}
+
+func TestClientRateLimiterInventedSessions(t *testing.T) {
+	limiter := newClientRateLimiter(0.001, 2, "session", newSessionRegistry())
+	handler := limiter.middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	codes := make([]int, 0, 3)
+	for _, id := range []string{"a", "b", "c"} {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/message?sessionId="+id, nil))
+		codes = append(codes, w.Code)
+	}
+	if codes[0] != http.StatusOK || codes[1] != http.StatusOK || codes[2] != http.StatusTooManyRequests {
+		t.Errorf("statuses = %v, want the third invented session limited", codes)
+	}
+	if len(limiter.clients) != 1 {
+		t.Errorf("%d buckets for one client, want 1", len(limiter.clients))
+	}
+}
+
+func TestClientRateLimiterEvict(t *testing.T) {
+	registry := newSessionRegistry()
+	registry.sessions["open"] = &registeredSession{}
+	limiter := newClientRateLimiter(1, 1, "session", registry)
+	for _, key := range []string{"session:open", "session:ended", "ip:192.0.2.1"} {
+		limiter.allow(key)
+	}
+	limiter.clients["ip:192.0.2.2"] = &clientLimiter{lastSeen: time.Now().Add(-2 * limiter.idleTTL)}
+
+	limiter.evict()
+	for key, want := range map[string]bool{"session:open": true, "session:ended": false, "ip:192.0.2.1": true, "ip:192.0.2.2": false} {
+		if _, ok := limiter.clients[key]; ok != want {
+			t.Errorf("bucket %s kept = %v, want %v", key, ok, want)
+		}
+	}
+}
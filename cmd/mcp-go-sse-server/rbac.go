@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	mcpserver "mcp-go-sse-server/pkg/server"
+)
+
+// ALL_TOOLS grants every tool to a role.
+const ALL_TOOLS = "*"
+
+// toolPolicy maps roles to the tools they may list and call. A caller's roles
+// come from the roles claim of its JWT, and from the principals table keyed
+// by the token subject or Basic auth user name. Callers without any role,
+// including stdio clients and API key holders, get DefaultRoles.
+type toolPolicy struct {
+	Roles        map[string][]string `json:"roles"`
+	Principals   map[string][]string `json:"principals,omitempty"`
+	DefaultRoles []string            `json:"default_roles,omitempty"`
+	// RolesClaim names the JWT claim listing the caller's roles, "roles" by
+	// default.
+	RolesClaim string `json:"roles_claim,omitempty"`
+}
+
+// loadToolPolicy reads and validates a role to tool mapping from a JSON file.
+func loadToolPolicy(path string) (*toolPolicy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tool policy: %w", err)
+	}
+	var policy toolPolicy
+	if err := json.Unmarshal(data, &policy); err != nil {
+		return nil, fmt.Errorf("failed to parse tool policy: %w", err)
+	}
+	if policy.RolesClaim == "" {
+		policy.RolesClaim = "roles"
+	}
+	for principal, roles := range policy.Principals {
+		for _, role := range roles {
+			if _, ok := policy.Roles[role]; !ok {
+				return nil, fmt.Errorf("tool policy: principal %q has undefined role %q", principal, role)
+			}
+		}
+	}
+	for _, role := range policy.DefaultRoles {
+		if _, ok := policy.Roles[role]; !ok {
+			return nil, fmt.Errorf("tool policy: undefined default role %q", role)
+		}
+	}
+	return &policy, nil
+}
+
+// callerRoles returns the roles of the caller authenticated on ctx.
+func (p *toolPolicy) callerRoles(ctx context.Context) []string {
+	claims, _ := mcpserver.ClaimsFromContext(ctx)
+	var roles []string
+	switch claim := claims[p.RolesClaim].(type) {
+	case []interface{}:
+		for _, role := range claim {
+			if role, ok := role.(string); ok {
+				roles = append(roles, role)
+			}
+		}
+	case string:
+		roles = strings.Fields(claim)
+	}
+	if subject, ok := claims["sub"].(string); ok {
+		roles = append(roles, p.Principals[subject]...)
+	}
+	if len(roles) == 0 {
+		return p.DefaultRoles
+	}
+	return roles
+}
+
+// allowed reports whether one of roles grants the tool. Roles missing from
+// the policy grant nothing.
+func (p *toolPolicy) allowed(roles []string, tool string) bool {
+	for _, role := range roles {
+		for _, granted := range p.Roles[role] {
+			if granted == ALL_TOOLS || granted == tool {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// filter hides the tools the caller may not call from tools/list.
+func (p *toolPolicy) filter(ctx context.Context, tools []mcp.Tool) []mcp.Tool {
+	roles := p.callerRoles(ctx)
+	allowed := make([]mcp.Tool, 0, len(tools))
+	for _, tool := range tools {
+		if p.allowed(roles, tool.Name) {
+			allowed = append(allowed, tool)
+		}
+	}
+	return allowed
+}
+
+// middleware rejects tools/call requests for tools the caller may not call.
+func (p *toolPolicy) middleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if !p.allowed(p.callerRoles(ctx), request.Params.Name) {
+			return nil, fmt.Errorf("tool %q is not permitted for this caller", request.Params.Name)
+		}
+		return next(ctx, request)
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	mcpserver "mcp-go-sse-server/pkg/server"
+)
+
+func writeTestPolicy(t *testing.T, policy string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "policy.json")
+	if err := os.WriteFile(path, []byte(policy), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestToolPolicyDecisions(t *testing.T) {
+	policy, err := loadToolPolicy(writeTestPolicy(t, `{
+		"roles": {"analyst": ["echo", "add"], "admin": ["*"], "guest": ["echo"]},
+		"principals": {"alice": ["admin"], "bob": ["analyst"]},
+		"default_roles": ["guest"]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	withClaims := func(claims map[string]any) context.Context {
+		return mcpserver.WithClaims(context.Background(), claims)
+	}
+
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		tool   string
+		wantOK bool
+	}{
+		{"admin principal, any tool", withClaims(map[string]any{"sub": "alice"}), "longRunningOperation", true},
+		{"analyst principal, granted tool", withClaims(map[string]any{"sub": "bob"}), "add", true},
+		{"analyst principal, other tool", withClaims(map[string]any{"sub": "bob"}), "longRunningOperation", false},
+		{"roles claim list", withClaims(map[string]any{"sub": "carol", "roles": []any{"analyst"}}), "add", true},
+		{"roles claim string", withClaims(map[string]any{"sub": "carol", "roles": "guest admin"}), "getTinyImage", true},
+		{"roles claim adds to principal roles", withClaims(map[string]any{"sub": "bob", "roles": []any{"admin"}}), "getTinyImage", true},
+		{"undefined role grants nothing", withClaims(map[string]any{"sub": "carol", "roles": []any{"root"}}), "echo", false},
+		{"unknown principal gets default roles", withClaims(map[string]any{"sub": "mallory"}), "echo", true},
+		{"default roles are limited", withClaims(map[string]any{"sub": "mallory"}), "add", false},
+		{"anonymous gets default roles", context.Background(), "echo", true},
+		{"anonymous is limited", context.Background(), "add", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if ok := policy.allowed(policy.callerRoles(tt.ctx), tt.tool); ok != tt.wantOK {
+				t.Errorf("allowed(%s) = %v, want %v", tt.tool, ok, tt.wantOK)
+			}
+
+			request := mcp.CallToolRequest{}
+			request.Params.Name = tt.tool
+			called := false
+			_, err := policy.middleware(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				called = true
+				return &mcp.CallToolResult{}, nil
+			})(tt.ctx, request)
+			if called != tt.wantOK || (err == nil) != tt.wantOK {
+				t.Errorf("middleware ran the tool = %v, err = %v, want allowed %v", called, err, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestToolPolicyFilter(t *testing.T) {
+	policy, err := loadToolPolicy(writeTestPolicy(t, `{"roles": {"analyst": ["echo", "add"]}, "principals": {"bob": ["analyst"]}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tools := []mcp.Tool{{Name: "echo"}, {Name: "add"}, {Name: "longRunningOperation"}}
+
+	listed := policy.filter(mcpserver.WithClaims(context.Background(), map[string]any{"sub": "bob"}), tools)
+	if len(listed) != 2 || listed[0].Name != "echo" || listed[1].Name != "add" {
+		t.Errorf("filter() for bob = %v, want echo and add", listed)
+	}
+	if listed := policy.filter(context.Background(), tools); len(listed) != 0 {
+		t.Errorf("filter() without roles = %v, want none", listed)
+	}
+}
+
+func TestLoadToolPolicyErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy string
+	}{
+		{"invalid JSON", `{"roles": `},
+		{"undefined principal role", `{"roles": {"analyst": ["echo"]}, "principals": {"bob": ["admin"]}}`},
+		{"undefined default role", `{"roles": {"analyst": ["echo"]}, "default_roles": ["guest"]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := loadToolPolicy(writeTestPolicy(t, tt.policy)); err == nil {
+				t.Error("loadToolPolicy() succeeded")
+			}
+		})
+	}
+}
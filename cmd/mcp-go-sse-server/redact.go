@@ -0,0 +1,38 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	mcpserver "mcp-go-sse-server/pkg/server"
+)
+
+// loadRedactor reads redaction rules from a JSON file.
+func loadRedactor(path string) (*mcpserver.Redactor, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read redaction rules: %w", err)
+	}
+	var rules mcpserver.RedactRules
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("failed to parse redaction rules: %w", err)
+	}
+	return mcpserver.NewRedactor(rules)
+}
+
+// redactMiddleware masks sensitive data in tool results before they reach
+// the client.
+func redactMiddleware(redactor *mcpserver.Redactor) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			result, err := next(ctx, request)
+			redactor.RedactToolResult(result)
+			return result, err
+		}
+	}
+}
@@ -0,0 +1,264 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// sessionInfo describes a connected MCP session for /admin/sessions.
+type sessionInfo struct {
+	ID           string              `json:"id"`
+	Client       *mcp.Implementation `json:"client,omitempty"`
+	ConnectedAt  time.Time           `json:"connected_at"`
+	LastActivity time.Time           `json:"last_activity"`
+}
+
+type registeredSession struct {
+	session      server.ClientSession
+	connectedAt  time.Time
+	lastActivity time.Time
+}
+
+// streamableSession is implemented only by streamable HTTP sessions, which
+// outlive their requests and end when the client deletes them.
+type streamableSession interface {
+	UpgradeToSSEWhenReceiveNotification()
+}
+
+// sessionRegistry records the sessions registered with the MCP server,
+// across every transport, through server hooks.
+type sessionRegistry struct {
+	mu       sync.Mutex
+	sessions map[string]*registeredSession
+}
+
+func newSessionRegistry() *sessionRegistry {
+	return &sessionRegistry{sessions: make(map[string]*registeredSession)}
+}
+
+// addHooks registers the hooks that keep the registry up to date.
+func (r *sessionRegistry) addHooks(hooks *server.Hooks) {
+	hooks.AddOnRegisterSession(func(ctx context.Context, session server.ClientSession) {
+		now := time.Now()
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.sessions[session.SessionID()] = &registeredSession{
+			session:      session,
+			connectedAt:  now,
+			lastActivity: now,
+		}
+	})
+	hooks.AddOnUnregisterSession(func(ctx context.Context, session server.ClientSession) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		delete(r.sessions, session.SessionID())
+	})
+	hooks.AddBeforeAny(func(ctx context.Context, id any, method mcp.MCPMethod, message any) {
+		session := server.ClientSessionFromContext(ctx)
+		if session == nil {
+			return
+		}
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		if registered, ok := r.sessions[session.SessionID()]; ok {
+			registered.lastActivity = time.Now()
+		}
+	})
+}
+
+// has reports whether sessionID is registered with this process.
+func (r *sessionRegistry) has(sessionID string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, ok := r.sessions[sessionID]
+	return ok
+}
+
+// live returns the IDs of the registered sessions that are still in use:
+// those holding an SSE stream, and streamable HTTP sessions active within
+// window, since a client may drop those without deleting them.
+func (r *sessionRegistry) live(window time.Duration) []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var ids []string
+	for id, registered := range r.sessions {
+		if _, ok := registered.session.(streamableSession); ok && time.Since(registered.lastActivity) > window {
+			continue
+		}
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// list describes the registered sessions, oldest first. Client info is only
+// known once the session has been initialized.
+func (r *sessionRegistry) list() []sessionInfo {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	list := make([]sessionInfo, 0, len(r.sessions))
+	for id, registered := range r.sessions {
+		info := sessionInfo{
+			ID:           id,
+			ConnectedAt:  registered.connectedAt,
+			LastActivity: registered.lastActivity,
+		}
+		if withClientInfo, ok := registered.session.(server.SessionWithClientInfo); ok {
+			if client := withClientInfo.GetClientInfo(); client.Name != "" {
+				info.Client = &client
+			}
+		}
+		list = append(list, info)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ConnectedAt.Before(list[j].ConnectedAt) })
+	return list
+}
+
+// sessionTracker counts active SSE connections and gRPC streams and enforces
+// the optional concurrent session limit, which also covers the open
+// streamable HTTP sessions.
+type sessionTracker struct {
+	registry   *sessionRegistry
+	streamable *streamableSessions
+	adminToken string
+	max        int64
+	active     atomic.Int64
+	total      atomic.Int64
+	rejected   atomic.Int64
+	draining   atomic.Bool
+}
+
+func newSessionTracker(max int, registry *sessionRegistry, adminToken string) *sessionTracker {
+	t := &sessionTracker{registry: registry, adminToken: adminToken, max: int64(max)}
+	expvar.Publish("sse_sessions_active", expvar.Func(func() any { return t.active.Load() }))
+	expvar.Publish("sse_sessions_total", expvar.Func(func() any { return t.total.Load() }))
+	expvar.Publish("sse_sessions_rejected", expvar.Func(func() any { return t.rejected.Load() }))
+	return t
+}
+
+// middleware wraps the SSE endpoint. The wrapped handler blocks for the
+// lifetime of the stream, so the session is counted until it returns.
+func (t *sessionTracker) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if t.draining.Load() {
+			t.rejected.Add(1)
+			http.Error(w, "server is shutting down", http.StatusServiceUnavailable)
+			return
+		}
+		active := t.active.Add(1)
+		defer t.active.Add(-1)
+		if t.max > 0 && active+t.streamable.count() > t.max {
+			t.rejected.Add(1)
+			http.Error(w, fmt.Sprintf("maximum number of concurrent sessions (%d) reached", t.max), http.StatusServiceUnavailable)
+			return
+		}
+		t.total.Add(1)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// streamInterceptor applies the same accounting to gRPC streams, each of
+// which is one session.
+func (t *sessionTracker) streamInterceptor(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if t.draining.Load() {
+		t.rejected.Add(1)
+		return status.Error(codes.Unavailable, "server is shutting down")
+	}
+	active := t.active.Add(1)
+	defer t.active.Add(-1)
+	if t.max > 0 && active+t.streamable.count() > t.max {
+		t.rejected.Add(1)
+		return status.Errorf(codes.ResourceExhausted, "maximum number of concurrent sessions (%d) reached", t.max)
+	}
+	t.total.Add(1)
+	return handler(srv, stream)
+}
+
+// streamableMiddleware wraps the streamable HTTP endpoint, where a new
+// session is a request without an Mcp-Session-Id header.
+func (t *sessionTracker) streamableMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(server.HeaderKeySessionID) != "" || r.Method == http.MethodDelete {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if t.draining.Load() {
+			t.rejected.Add(1)
+			http.Error(w, "server is shutting down", http.StatusServiceUnavailable)
+			return
+		}
+		if t.max > 0 && t.active.Load()+t.streamable.count() >= t.max {
+			t.rejected.Add(1)
+			http.Error(w, fmt.Sprintf("maximum number of concurrent sessions (%d) reached", t.max), http.StatusServiceUnavailable)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// drain makes the tracker reject every new session.
+func (t *sessionTracker) drain() {
+	t.draining.Store(true)
+}
+
+// ready fails once the tracker is draining, so traffic is routed away
+// during shutdown.
+func (t *sessionTracker) ready(ctx context.Context) error {
+	if t.draining.Load() {
+		return fmt.Errorf("shutting down")
+	}
+	return nil
+}
+
+// authorizeAdmin checks the admin token, when one is set, and answers 401
+// when the request does not carry it as a bearer token.
+func authorizeAdmin(w http.ResponseWriter, r *http.Request, adminToken string) bool {
+	if adminToken == "" {
+		return true
+	}
+	token := r.Header.Get("Authorization")
+	if subtle.ConstantTimeCompare([]byte(token), []byte("Bearer "+adminToken)) != 1 {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return false
+	}
+	return true
+}
+
+// handleAdmin reports the session counters and every registered session.
+// With an admin token set, it requires it as a bearer token.
+func (t *sessionTracker) handleAdmin(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !authorizeAdmin(w, r, t.adminToken) {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"active":            t.active.Load(),
+		"streamable_active": t.streamable.count(),
+		"max":               t.max,
+		"total":             t.total.Load(),
+		"rejected":          t.rejected.Load(),
+		"sessions":          t.registry.list(),
+	})
+}
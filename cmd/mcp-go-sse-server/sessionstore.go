@@ -0,0 +1,197 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/redis/go-redis/v9"
+)
+
+// FORWARDED_SESSION_HEADER marks a message already routed by another
+// replica, so it is never forwarded twice.
+const FORWARDED_SESSION_HEADER = "X-Mcp-Forwarded-By"
+
+// sessionStoreTTL bounds how long a session outlives a replica that died
+// without unregistering it. Live sessions are refreshed well before.
+const sessionStoreTTL = time.Minute
+
+// sessionStoreActivityWindow is how long a streamable HTTP session stays
+// published without activity. Clients of a session that has expired from the
+// store get 404 from other replicas and start a new one.
+const sessionStoreActivityWindow = 30 * time.Minute
+
+var errSessionNotFound = errors.New("session not found")
+
+// sessionStore records which replica holds the stream of each session.
+type sessionStore interface {
+	Register(ctx context.Context, sessionID string, owner string) error
+	Lookup(ctx context.Context, sessionID string) (string, error)
+	Unregister(ctx context.Context, sessionID string) error
+}
+
+// redisSessionStore keeps session owners in Redis keys that expire unless
+// refreshed.
+type redisSessionStore struct {
+	client *redis.Client
+}
+
+func newRedisSessionStore(rawURL string) (*redisSessionStore, error) {
+	options, err := redis.ParseURL(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid session store URL: %w", err)
+	}
+	return &redisSessionStore{client: redis.NewClient(options)}, nil
+}
+
+func (s *redisSessionStore) key(sessionID string) string {
+	return "mcp:session:" + sessionID
+}
+
+func (s *redisSessionStore) Register(ctx context.Context, sessionID string, owner string) error {
+	return s.client.Set(ctx, s.key(sessionID), owner, sessionStoreTTL).Err()
+}
+
+func (s *redisSessionStore) Lookup(ctx context.Context, sessionID string) (string, error) {
+	owner, err := s.client.Get(ctx, s.key(sessionID)).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", errSessionNotFound
+	}
+	return owner, err
+}
+
+func (s *redisSessionStore) Unregister(ctx context.Context, sessionID string) error {
+	return s.client.Del(ctx, s.key(sessionID)).Err()
+}
+
+// sessionRouter publishes the sessions of this replica to a shared store and
+// forwards messages for sessions held by another replica to it.
+type sessionRouter struct {
+	store    sessionStore
+	self     string
+	registry *sessionRegistry
+	mu       sync.Mutex
+	proxies  map[string]*httputil.ReverseProxy
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+func newSessionRouter(store sessionStore, self string, registry *sessionRegistry) *sessionRouter {
+	r := &sessionRouter{
+		store:    store,
+		self:     self,
+		registry: registry,
+		proxies:  make(map[string]*httputil.ReverseProxy),
+		done:     make(chan struct{}),
+	}
+	go r.refresh()
+	return r
+}
+
+// addHooks registers the hooks that publish sessions to the store. They
+// use their own context because the request may already be gone when a
+// session is unregistered.
+func (r *sessionRouter) addHooks(hooks *server.Hooks) {
+	hooks.AddOnRegisterSession(func(_ context.Context, session server.ClientSession) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := r.store.Register(ctx, session.SessionID(), r.self); err != nil {
+			log.Printf("Session store: failed to register session %s: %v", session.SessionID(), err)
+		}
+	})
+	hooks.AddOnUnregisterSession(func(_ context.Context, session server.ClientSession) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := r.store.Unregister(ctx, session.SessionID()); err != nil {
+			log.Printf("Session store: failed to unregister session %s: %v", session.SessionID(), err)
+		}
+	})
+}
+
+// refresh re-registers the live local sessions before their entries expire,
+// until the router is stopped.
+func (r *sessionRouter) refresh() {
+	ticker := time.NewTicker(sessionStoreTTL / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.done:
+			return
+		case <-ticker.C:
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), sessionStoreTTL/3)
+		for _, id := range r.registry.live(sessionStoreActivityWindow) {
+			if err := r.store.Register(ctx, id, r.self); err != nil {
+				log.Printf("Session store: failed to refresh session %s: %v", id, err)
+			}
+		}
+		cancel()
+	}
+}
+
+// stop ends the refresh loop. A nil router has nothing to stop.
+func (r *sessionRouter) stop() {
+	if r == nil {
+		return
+	}
+	r.stopOnce.Do(func() { close(r.done) })
+}
+
+func (r *sessionRouter) proxy(owner string) (*httputil.ReverseProxy, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if proxy, ok := r.proxies[owner]; ok {
+		return proxy, nil
+	}
+	target, err := url.Parse(owner)
+	if err != nil {
+		return nil, err
+	}
+	proxy := httputil.NewSingleHostReverseProxy(target)
+	r.proxies[owner] = proxy
+	return proxy, nil
+}
+
+// middleware forwards a message to the replica holding its session. Requests
+// without a session, for local or unknown sessions, and requests already
+// forwarded once reach next. A nil router forwards nothing.
+func (r *sessionRouter) middleware(next http.Handler) http.Handler {
+	if r == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		sessionID := req.URL.Query().Get("sessionId")
+		if sessionID == "" {
+			sessionID = req.Header.Get(server.HeaderKeySessionID)
+		}
+		if sessionID == "" || req.Header.Get(FORWARDED_SESSION_HEADER) != "" || r.registry.has(sessionID) {
+			next.ServeHTTP(w, req)
+			return
+		}
+		owner, err := r.store.Lookup(req.Context(), sessionID)
+		if errors.Is(err, errSessionNotFound) || (err == nil && owner == r.self) {
+			next.ServeHTTP(w, req)
+			return
+		}
+		if err != nil {
+			log.Printf("Session store: failed to look up session %s: %v", sessionID, err)
+			http.Error(w, "session store unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		proxy, err := r.proxy(owner)
+		if err != nil {
+			log.Printf("Session store: invalid owner %q for session %s: %v", owner, sessionID, err)
+			http.Error(w, "session store unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		req.Header.Set(FORWARDED_SESSION_HEADER, r.self)
+		proxy.ServeHTTP(w, req)
+	})
+}
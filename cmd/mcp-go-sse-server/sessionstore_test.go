@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// memorySessionStore is a sessionStore shared by the routers of a test.
+type memorySessionStore struct {
+	mu     sync.Mutex
+	owners map[string]string
+	err    error
+}
+
+func newMemorySessionStore() *memorySessionStore {
+	return &memorySessionStore{owners: make(map[string]string)}
+}
+
+func (s *memorySessionStore) Register(ctx context.Context, sessionID string, owner string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.owners[sessionID] = owner
+	return nil
+}
+
+func (s *memorySessionStore) Lookup(ctx context.Context, sessionID string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return "", s.err
+	}
+	owner, ok := s.owners[sessionID]
+	if !ok {
+		return "", errSessionNotFound
+	}
+	return owner, nil
+}
+
+func (s *memorySessionStore) Unregister(ctx context.Context, sessionID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.owners, sessionID)
+	return nil
+}
+
+func TestSessionRouterMiddleware(t *testing.T) {
+	forwarded := make(chan *http.Request, 1)
+	owner := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		forwarded <- r
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer owner.Close()
+
+	store := newMemorySessionStore()
+	store.owners["remote"] = owner.URL
+	store.owners["mine"] = "http://self.example.com"
+	registry := newSessionRegistry()
+	registry.sessions["local"] = &registeredSession{}
+	router := newSessionRouter(store, "http://self.example.com", registry)
+	defer router.stop()
+	handler := router.middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name          string
+		target        string
+		header        http.Header
+		wantStatus    int
+		wantForwarded bool
+	}{
+		{"no session", "/message", nil, http.StatusOK, false},
+		{"local session", "/message?sessionId=local", nil, http.StatusOK, false},
+		{"remote session", "/message?sessionId=remote", nil, http.StatusAccepted, true},
+		{"remote streamable session", "/mcp", http.Header{server.HeaderKeySessionID: {"remote"}}, http.StatusAccepted, true},
+		{"already forwarded", "/message?sessionId=remote", http.Header{FORWARDED_SESSION_HEADER: {"http://other.example.com"}}, http.StatusOK, false},
+		{"unknown session", "/message?sessionId=unknown", nil, http.StatusOK, false},
+		{"published by this replica", "/message?sessionId=mine", nil, http.StatusOK, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, tt.target, nil)
+			for key, values := range tt.header {
+				r.Header[key] = values
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			select {
+			case got := <-forwarded:
+				if !tt.wantForwarded {
+					t.Fatal("request was forwarded")
+				}
+				if got.URL.RequestURI() != tt.target {
+					t.Errorf("forwarded to %s, want %s", got.URL.RequestURI(), tt.target)
+				}
+				if got.Header.Get(FORWARDED_SESSION_HEADER) != "http://self.example.com" {
+					t.Errorf("%s = %q, want this replica", FORWARDED_SESSION_HEADER, got.Header.Get(FORWARDED_SESSION_HEADER))
+				}
+			default:
+				if tt.wantForwarded {
+					t.Fatal("request was not forwarded")
+				}
+			}
+		})
+	}
+}
+
+func TestSessionRouterStoreUnavailable(t *testing.T) {
+	store := newMemorySessionStore()
+	store.err = errors.New("connection refused")
+	router := newSessionRouter(store, "http://self.example.com", newSessionRegistry())
+	defer router.stop()
+	handler := router.middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/message?sessionId=remote", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusServiceUnavailable)
+	}
+}
+
+func TestSessionRouterHooks(t *testing.T) {
+	store := newMemorySessionStore()
+	router := newSessionRouter(store, "http://self.example.com", newSessionRegistry())
+	router.stop()
+	router.stop()
+	hooks := &server.Hooks{}
+	router.addHooks(hooks)
+
+	session := &grpcSession{id: "s1"}
+	for _, hook := range hooks.OnRegisterSession {
+		hook(context.Background(), session)
+	}
+	if owner, err := store.Lookup(context.Background(), "s1"); err != nil || owner != "http://self.example.com" {
+		t.Errorf("registered owner = %q, %v, want this replica", owner, err)
+	}
+	for _, hook := range hooks.OnUnregisterSession {
+		hook(context.Background(), session)
+	}
+	if _, err := store.Lookup(context.Background(), "s1"); !errors.Is(err, errSessionNotFound) {
+		t.Errorf("Lookup() after unregister error = %v, want errSessionNotFound", err)
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// toolCallTracker keeps count of running tool calls so shutdown can wait for
+// them to finish. Once it drains, new calls are refused so the count can only
+// go down.
+type toolCallTracker struct {
+	mu       sync.Mutex
+	idle     *sync.Cond
+	running  int
+	draining bool
+}
+
+func newToolCallTracker() *toolCallTracker {
+	t := &toolCallTracker{}
+	t.idle = sync.NewCond(&t.mu)
+	return t
+}
+
+func (t *toolCallTracker) middleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		t.mu.Lock()
+		if t.draining {
+			t.mu.Unlock()
+			return mcp.NewToolResultError("Server is shutting down, try again later."), nil
+		}
+		t.running++
+		t.mu.Unlock()
+		defer func() {
+			t.mu.Lock()
+			t.running--
+			t.idle.Broadcast()
+			t.mu.Unlock()
+		}()
+		return next(ctx, request)
+	}
+}
+
+// wait refuses new tool calls and blocks until every running one has
+// returned or ctx is done.
+func (t *toolCallTracker) wait(ctx context.Context) error {
+	stop := context.AfterFunc(ctx, func() {
+		t.mu.Lock()
+		t.idle.Broadcast()
+		t.mu.Unlock()
+	})
+	defer stop()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.draining = true
+	for t.running > 0 && ctx.Err() == nil {
+		t.idle.Wait()
+	}
+	return ctx.Err()
+}
+
+// gracefulShutdown stops accepting new sessions, fails the calls awaiting
+// approval, waits for running tool calls and then closes the transports.
+// stopStreams ends the long-lived SSE and stdio streams, which would
+// otherwise keep the HTTP servers from shutting down.
+func gracefulShutdown(
+	ctx context.Context,
+	sessions *sessionTracker,
+	approvals *approvalGate,
+	toolCalls *toolCallTracker,
+	stopStreams context.CancelFunc,
+	httpServers ...*http.Server,
+) {
+	if sessions != nil {
+		sessions.drain()
+	}
+	approvals.drain()
+	if err := toolCalls.wait(ctx); err != nil {
+		log.Printf("Shutdown: gave up waiting for running tool calls: %v", err)
+	}
+	stopStreams()
+	for _, httpServer := range httpServers {
+		if httpServer == nil {
+			continue
+		}
+		if err := httpServer.Shutdown(ctx); err != nil {
+			log.Printf("Shutdown: HTTP server: %v", err)
+		}
+	}
+}
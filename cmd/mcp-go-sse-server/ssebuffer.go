@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SSE buffer overflow policies.
+const (
+	SSE_BUFFER_DROP_OLDEST = "drop-oldest"
+	SSE_BUFFER_DROP_NEWEST = "drop-newest"
+	SSE_BUFFER_DISCONNECT  = "disconnect"
+)
+
+var sseEventsDropped = expvar.NewInt("sse_events_dropped")
+
+// sseBuffering bounds the events queued for each SSE stream. Events are
+// written to the client by a separate goroutine, so a slow client never
+// blocks the server, and the overflow policy decides what happens once its
+// queue is full.
+type sseBuffering struct {
+	size   int
+	policy string
+}
+
+func newSSEBuffering(size int, policy string) (*sseBuffering, error) {
+	switch policy {
+	case SSE_BUFFER_DROP_OLDEST, SSE_BUFFER_DROP_NEWEST, SSE_BUFFER_DISCONNECT:
+		return &sseBuffering{size: size, policy: policy}, nil
+	default:
+		return nil, fmt.Errorf("unknown SSE buffer policy %q, expected %s, %s or %s",
+			policy, SSE_BUFFER_DROP_OLDEST, SSE_BUFFER_DROP_NEWEST, SSE_BUFFER_DISCONNECT)
+	}
+}
+
+// sseEvent is one queued write. Only notifications may be dropped: losing a
+// response or a server request would leave the peer waiting forever.
+type sseEvent struct {
+	data      []byte
+	droppable bool
+}
+
+func newSSEEvent(p []byte) sseEvent {
+	event := sseEvent{data: append([]byte(nil), p...)}
+	for _, line := range strings.Split(string(p), "\n") {
+		data, ok := strings.CutPrefix(line, "data:")
+		if !ok {
+			continue
+		}
+		var message struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		if json.Unmarshal([]byte(strings.TrimSpace(data)), &message) == nil {
+			event.droppable = message.Method != "" && message.ID == nil
+		}
+	}
+	return event
+}
+
+// sseBufferWriter queues the writes of the SSE handler for drain.
+type sseBufferWriter struct {
+	http.ResponseWriter
+	buffering *sseBuffering
+	cancel    context.CancelFunc
+	mu        sync.Mutex
+	events    []sseEvent
+	closed    bool
+	wake      chan struct{}
+}
+
+// disconnect ends the stream. The write deadline unblocks a write stuck on
+// a client that stopped reading. Called with w.mu held.
+func (w *sseBufferWriter) disconnect() {
+	if w.closed {
+		return
+	}
+	w.closed = true
+	log.Printf("SSE client too slow, closing stream with %d queued events", len(w.events))
+	w.events = nil
+	w.cancel()
+	http.NewResponseController(w.ResponseWriter).SetWriteDeadline(time.Now())
+}
+
+func (w *sseBufferWriter) Write(p []byte) (int, error) {
+	event := newSSEEvent(p)
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return len(p), nil
+	}
+	if len(w.events) >= w.buffering.size {
+		switch {
+		case w.buffering.policy == SSE_BUFFER_DROP_NEWEST && event.droppable:
+			w.mu.Unlock()
+			sseEventsDropped.Add(1)
+			return len(p), nil
+		case w.buffering.policy == SSE_BUFFER_DISCONNECT || !w.dropOldest():
+			w.disconnect()
+			w.mu.Unlock()
+			return len(p), nil
+		}
+	}
+	w.events = append(w.events, event)
+	w.mu.Unlock()
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+	return len(p), nil
+}
+
+// dropOldest removes the oldest queued notification. It reports false when
+// the queue holds none.
+func (w *sseBufferWriter) dropOldest() bool {
+	for i, queued := range w.events {
+		if queued.droppable {
+			w.events = append(w.events[:i], w.events[i+1:]...)
+			sseEventsDropped.Add(1)
+			return true
+		}
+	}
+	return false
+}
+
+// Flush is a no-op: drain flushes after every event it writes.
+func (w *sseBufferWriter) Flush() {}
+
+func (w *sseBufferWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// drain writes queued events to the client until ctx is done.
+func (w *sseBufferWriter) drain(ctx context.Context) {
+	flusher, _ := w.ResponseWriter.(http.Flusher)
+	for {
+		select {
+		case <-w.wake:
+		case <-ctx.Done():
+			return
+		}
+		w.mu.Lock()
+		events := w.events
+		w.events = nil
+		w.mu.Unlock()
+		for _, event := range events {
+			if _, err := w.ResponseWriter.Write(event.data); err != nil {
+				w.cancel()
+				return
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+	}
+}
+
+// middleware wraps the SSE endpoint. A nil *sseBuffering leaves it unbuffered.
+func (b *sseBuffering) middleware(next http.Handler) http.Handler {
+	if b == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			next.ServeHTTP(w, r)
+			return
+		}
+		ctx, cancel := context.WithCancel(r.Context())
+		defer cancel()
+		writer := &sseBufferWriter{
+			ResponseWriter: w,
+			buffering:      b,
+			cancel:         cancel,
+			wake:           make(chan struct{}, 1),
+		}
+		drained := make(chan struct{})
+		go func() {
+			defer close(drained)
+			writer.drain(ctx)
+		}()
+		next.ServeHTTP(writer, r.WithContext(ctx))
+		cancel()
+		<-drained
+	})
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func sseTestEvent(message string) string {
+	return fmt.Sprintf("event: message\ndata: %s\n\n", message)
+}
+
+// Notifications n1..n3 may be dropped, responses r1..r3 may not.
+var sseTestEvents = map[string]string{
+	"n1": sseTestEvent(`{"jsonrpc":"2.0","method":"notifications/message","params":{"n":1}}`),
+	"n2": sseTestEvent(`{"jsonrpc":"2.0","method":"notifications/message","params":{"n":2}}`),
+	"n3": sseTestEvent(`{"jsonrpc":"2.0","method":"notifications/message","params":{"n":3}}`),
+	"r1": sseTestEvent(`{"jsonrpc":"2.0","id":1,"result":{}}`),
+	"r2": sseTestEvent(`{"jsonrpc":"2.0","id":2,"result":{}}`),
+	"r3": sseTestEvent(`{"jsonrpc":"2.0","id":3,"result":{}}`),
+}
+
+func TestNewSSEEvent(t *testing.T) {
+	tests := []struct {
+		name          string
+		event         string
+		wantDroppable bool
+	}{
+		{"notification", sseTestEvents["n1"], true},
+		{"response", sseTestEvents["r1"], false},
+		{"error response", sseTestEvent(`{"jsonrpc":"2.0","id":"a","error":{"code":-1,"message":"x"}}`), false},
+		{"server request", sseTestEvent(`{"jsonrpc":"2.0","id":7,"method":"sampling/createMessage"}`), false},
+		{"endpoint", "event: endpoint\ndata: /message?sessionId=abc\n\n", false},
+		{"ping comment", ": ping\n\n", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := newSSEEvent([]byte(tt.event))
+			if event.droppable != tt.wantDroppable {
+				t.Errorf("droppable = %v, want %v", event.droppable, tt.wantDroppable)
+			}
+			if string(event.data) != tt.event {
+				t.Errorf("data = %q, want %q", event.data, tt.event)
+			}
+		})
+	}
+}
+
+func TestSSEBufferWriterPolicies(t *testing.T) {
+	tests := []struct {
+		name           string
+		policy         string
+		writes         string
+		wantQueued     string
+		wantDisconnect bool
+	}{
+		{"under the limit", SSE_BUFFER_DROP_OLDEST, "n1 r1", "n1 r1", false},
+		{"drop oldest notification", SSE_BUFFER_DROP_OLDEST, "n1 n2 n3", "n2 n3", false},
+		{"drop oldest keeps responses", SSE_BUFFER_DROP_OLDEST, "r1 n1 n2", "r1 n2", false},
+		{"drop oldest without notifications", SSE_BUFFER_DROP_OLDEST, "r1 r2 r3", "", true},
+		{"drop newest notification", SSE_BUFFER_DROP_NEWEST, "n1 n2 n3", "n1 n2", false},
+		{"drop newest makes room for a response", SSE_BUFFER_DROP_NEWEST, "n1 n2 r1", "n2 r1", false},
+		{"drop newest without notifications", SSE_BUFFER_DROP_NEWEST, "r1 r2 r3", "", true},
+		{"disconnect", SSE_BUFFER_DISCONNECT, "n1 n2 n3", "", true},
+		{"writes after disconnect are discarded", SSE_BUFFER_DISCONNECT, "r1 r2 r3 n1", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buffering, err := newSSEBuffering(2, tt.policy)
+			if err != nil {
+				t.Fatal(err)
+			}
+			disconnected := false
+			w := &sseBufferWriter{
+				ResponseWriter: httptest.NewRecorder(),
+				buffering:      buffering,
+				cancel:         func() { disconnected = true },
+				wake:           make(chan struct{}, 1),
+			}
+			for _, name := range strings.Fields(tt.writes) {
+				if n, err := w.Write([]byte(sseTestEvents[name])); err != nil || n != len(sseTestEvents[name]) {
+					t.Fatalf("Write(%s) = %d, %v", name, n, err)
+				}
+			}
+			var queued []string
+			for _, event := range w.events {
+				for name, data := range sseTestEvents {
+					if string(event.data) == data {
+						queued = append(queued, name)
+					}
+				}
+			}
+			if got := strings.Join(queued, " "); got != tt.wantQueued {
+				t.Errorf("queued = %q, want %q", got, tt.wantQueued)
+			}
+			if disconnected != tt.wantDisconnect {
+				t.Errorf("disconnected = %v, want %v", disconnected, tt.wantDisconnect)
+			}
+		})
+	}
+}
+
+func TestNewSSEBufferingRejectsUnknownPolicy(t *testing.T) {
+	if _, err := newSSEBuffering(10, "block"); err == nil {
+		t.Error("newSSEBuffering() accepted an unknown policy")
+	}
+}
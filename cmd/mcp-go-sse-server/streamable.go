@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+const streamableSessionPrefix = "mcp-session-"
+
+// streamableSessions is the session ID manager of the streamable HTTP
+// transport. Unlike SSE sessions, which end with their stream, streamable
+// sessions last until the client deletes them, so they are tracked here for
+// --max-sessions and --session-idle-timeout.
+type streamableSessions struct {
+	timeout time.Duration
+	// handler is the streamable HTTP server, which idle sessions are deleted
+	// through so it releases their state.
+	handler http.Handler
+	path    string
+
+	mu       sync.Mutex
+	sessions map[string]time.Time
+}
+
+// newStreamableSessions expires sessions without requests for longer than
+// timeout once started. A zero timeout keeps them until the client deletes
+// them.
+func newStreamableSessions(timeout time.Duration) *streamableSessions {
+	return &streamableSessions{timeout: timeout, sessions: make(map[string]time.Time)}
+}
+
+// start begins expiring idle sessions through handler, served at path.
+func (s *streamableSessions) start(handler http.Handler, path string) {
+	s.handler = handler
+	s.path = path
+	if s.timeout > 0 {
+		go s.sweep()
+	}
+}
+
+func (s *streamableSessions) Generate() string {
+	id := streamableSessionPrefix + uuid.NewString()
+	s.mu.Lock()
+	s.sessions[id] = time.Now()
+	s.mu.Unlock()
+	return id
+}
+
+// Validate accepts open sessions and marks them as active.
+func (s *streamableSessions) Validate(sessionID string) (bool, error) {
+	if !strings.HasPrefix(sessionID, streamableSessionPrefix) {
+		return false, fmt.Errorf("invalid session id: %s", sessionID)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.sessions[sessionID]; !ok {
+		return false, fmt.Errorf("session not found: %s", sessionID)
+	}
+	s.sessions[sessionID] = time.Now()
+	return false, nil
+}
+
+func (s *streamableSessions) Terminate(sessionID string) (bool, error) {
+	s.mu.Lock()
+	delete(s.sessions, sessionID)
+	s.mu.Unlock()
+	return false, nil
+}
+
+// count returns the number of open sessions. A nil *streamableSessions has
+// none.
+func (s *streamableSessions) count() int64 {
+	if s == nil {
+		return 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return int64(len(s.sessions))
+}
+
+func (s *streamableSessions) sweep() {
+	for range time.Tick(max(s.timeout/2, time.Second)) {
+		var idle []string
+		s.mu.Lock()
+		for id, lastActive := range s.sessions {
+			if time.Since(lastActive) > s.timeout {
+				idle = append(idle, id)
+			}
+		}
+		s.mu.Unlock()
+		for _, id := range idle {
+			log.Printf("Closing streamable HTTP session %s after %s without activity", id, s.timeout)
+			s.delete(id)
+		}
+	}
+}
+
+// delete ends a session the way a client DELETE does.
+func (s *streamableSessions) delete(sessionID string) {
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodDelete, s.path, nil)
+	if err != nil {
+		return
+	}
+	req.Header.Set(server.HeaderKeySessionID, sessionID)
+	s.handler.ServeHTTP(httptest.NewRecorder(), req)
+}
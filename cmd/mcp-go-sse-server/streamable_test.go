@@ -0,0 +1,125 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+func TestStreamableSessions(t *testing.T) {
+	sessions := newStreamableSessions(0)
+	id := sessions.Generate()
+	if !strings.HasPrefix(id, streamableSessionPrefix) {
+		t.Errorf("Generate() = %q, want the %s prefix", id, streamableSessionPrefix)
+	}
+	if other := sessions.Generate(); other == id {
+		t.Error("Generate() returned the same ID twice")
+	}
+	if got := sessions.count(); got != 2 {
+		t.Errorf("count() = %d, want 2", got)
+	}
+
+	tests := []struct {
+		name    string
+		id      string
+		wantErr bool
+	}{
+		{"open session", id, false},
+		{"unknown session", streamableSessionPrefix + "unknown", true},
+		{"foreign ID", "something-else", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			terminated, err := sessions.Validate(tt.id)
+			if terminated || (err != nil) != tt.wantErr {
+				t.Errorf("Validate() = %v, %v, want error %v", terminated, err, tt.wantErr)
+			}
+		})
+	}
+
+	sessions.Terminate(id)
+	if _, err := sessions.Validate(id); err == nil {
+		t.Error("Validate() accepted a terminated session")
+	}
+	if got := sessions.count(); got != 1 {
+		t.Errorf("count() after Terminate = %d, want 1", got)
+	}
+	var none *streamableSessions
+	if got := none.count(); got != 0 {
+		t.Errorf("nil count() = %d, want 0", got)
+	}
+}
+
+func TestStreamableSessionsIdleExpiry(t *testing.T) {
+	sessions := newStreamableSessions(100 * time.Millisecond)
+	deleted := make(chan string, 2)
+	sessions.start(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete || r.URL.Path != "/mcp" {
+			t.Errorf("idle session closed with %s %s, want DELETE /mcp", r.Method, r.URL.Path)
+		}
+		// The transport terminates the session through the manager.
+		id := r.Header.Get(server.HeaderKeySessionID)
+		sessions.Terminate(id)
+		deleted <- id
+	}), "/mcp")
+
+	idle := sessions.Generate()
+	active := sessions.Generate()
+	deadline := time.After(5 * time.Second)
+	for {
+		// Requests keep the active session open.
+		if _, err := sessions.Validate(active); err != nil {
+			t.Fatalf("active session expired: %v", err)
+		}
+		select {
+		case id := <-deleted:
+			if id != idle {
+				t.Fatalf("deleted session %q, want the idle one %q", id, idle)
+			}
+			if got := sessions.count(); got != 1 {
+				t.Errorf("count() after expiry = %d, want 1", got)
+			}
+			return
+		case <-deadline:
+			t.Fatal("idle session was not deleted")
+		case <-time.After(20 * time.Millisecond):
+		}
+	}
+}
+
+func TestSessionLimitCoversStreamableSessions(t *testing.T) {
+	// A literal tracker, since newSessionTracker publishes process-wide
+	// expvar counters.
+	sessions := &sessionTracker{registry: newSessionRegistry(), max: 2, streamable: newStreamableSessions(0)}
+	handler := sessions.streamableMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(server.HeaderKeySessionID) == "" {
+			sessions.streamable.Generate()
+		}
+	}))
+
+	initialize := func() int {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/mcp", nil))
+		return w.Code
+	}
+	for i := 0; i < 2; i++ {
+		if code := initialize(); code != http.StatusOK {
+			t.Fatalf("session %d: status = %d, want %d", i, code, http.StatusOK)
+		}
+	}
+	if code := initialize(); code != http.StatusServiceUnavailable {
+		t.Errorf("session over the limit: status = %d, want %d", code, http.StatusServiceUnavailable)
+	}
+	// Requests of open sessions are not new sessions.
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+	r.Header.Set(server.HeaderKeySessionID, streamableSessionPrefix+"x")
+	handler.ServeHTTP(w, r)
+	if w.Code != http.StatusOK {
+		t.Errorf("request of an open session: status = %d, want %d", w.Code, http.StatusOK)
+	}
+}
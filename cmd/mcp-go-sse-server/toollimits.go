@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// resultLimitMiddleware caps the bytes of text, image and audio data in a
+// tool result at max. Text is cut at the limit, binary content that does not
+// fit is dropped, and a notice is appended so the model knows the result is
+// incomplete.
+func resultLimitMiddleware(max int) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			result, err := next(ctx, request)
+			if result != nil {
+				truncateResult(result, max)
+			}
+			return result, err
+		}
+	}
+}
+
+func truncateResult(result *mcp.CallToolResult, max int) {
+	total, returned := 0, 0
+	kept := make([]mcp.Content, 0, len(result.Content))
+	for _, content := range result.Content {
+		switch c := content.(type) {
+		case mcp.TextContent:
+			total += len(c.Text)
+			c.Text = truncateUTF8(c.Text, max-returned)
+			returned += len(c.Text)
+			if c.Text != "" {
+				kept = append(kept, c)
+			}
+			continue
+		case mcp.ImageContent:
+			total += len(c.Data)
+			if returned+len(c.Data) > max {
+				continue
+			}
+			returned += len(c.Data)
+		case mcp.AudioContent:
+			total += len(c.Data)
+			if returned+len(c.Data) > max {
+				continue
+			}
+			returned += len(c.Data)
+		}
+		kept = append(kept, content)
+	}
+	if total <= max {
+		return
+	}
+	result.Content = append(kept, mcp.NewTextContent(fmt.Sprintf(
+		"[Result truncated: %d of %d bytes returned. Narrow the request to get the rest.]", returned, total)))
+}
+
+// timeoutMiddleware cancels the context of tool calls that run longer than
+// timeout and turns the cancellation into a tool error. Tools stop early only
+// if they watch their context.
+func timeoutMiddleware(timeout time.Duration) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			result, err := next(ctx, request)
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return mcp.NewToolResultError(fmt.Sprintf("Tool %s timed out after %s.", request.Params.Name, timeout)), nil
+			}
+			return result, err
+		}
+	}
+}
+
+// truncateUTF8 cuts s to at most n bytes without splitting a rune.
+func truncateUTF8(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
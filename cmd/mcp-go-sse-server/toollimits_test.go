@@ -0,0 +1,81 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// toolLimitsContent builds content from "text:...", "image:..." and
+// "audio:..." descriptions, which describeContent turns it back into.
+func toolLimitsContent(descriptions string) []mcp.Content {
+	var content []mcp.Content
+	for _, description := range strings.Fields(descriptions) {
+		kind, data, _ := strings.Cut(description, ":")
+		switch kind {
+		case "text":
+			content = append(content, mcp.NewTextContent(data))
+		case "image":
+			content = append(content, mcp.NewImageContent(data, "image/png"))
+		case "audio":
+			content = append(content, mcp.NewAudioContent(data, "audio/wav"))
+		}
+	}
+	return content
+}
+
+func describeContent(content []mcp.Content) string {
+	var descriptions []string
+	for _, c := range content {
+		switch c := c.(type) {
+		case mcp.TextContent:
+			descriptions = append(descriptions, "text:"+c.Text)
+		case mcp.ImageContent:
+			descriptions = append(descriptions, "image:"+c.Data)
+		case mcp.AudioContent:
+			descriptions = append(descriptions, "audio:"+c.Data)
+		}
+	}
+	return strings.Join(descriptions, " ")
+}
+
+func TestTruncateResult(t *testing.T) {
+	tests := []struct {
+		name       string
+		content    string
+		max        int
+		want       string
+		wantNotice string
+	}{
+		{"under the limit", "text:hello image:xx", 10, "text:hello image:xx", ""},
+		{"at the limit", "text:hello", 5, "text:hello", ""},
+		{"text cut", "text:helloworld", 5, "text:hello", "5 of 10 bytes"},
+		{"rune not split", "text:héllo", 2, "text:h", "1 of 6 bytes"},
+		{"second text cut", "text:abc text:def", 4, "text:abc text:d", "4 of 6 bytes"},
+		{"text after the limit dropped", "text:abcd text:ef", 4, "text:abcd", "4 of 6 bytes"},
+		{"image over the limit dropped", "text:ab image:xxxx", 4, "text:ab", "2 of 6 bytes"},
+		{"smaller image still fits", "image:xxxxxx image:xx", 4, "image:xx", "2 of 8 bytes"},
+		{"audio over the limit dropped", "audio:xxxxxx", 4, "", "0 of 6 bytes"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := &mcp.CallToolResult{Content: toolLimitsContent(tt.content)}
+			truncateResult(result, tt.max)
+			content := result.Content
+			if tt.wantNotice != "" {
+				if len(content) == 0 {
+					t.Fatal("no truncation notice")
+				}
+				notice, ok := content[len(content)-1].(mcp.TextContent)
+				if !ok || !strings.Contains(notice.Text, tt.wantNotice) {
+					t.Fatalf("notice = %v, want one mentioning %q", content[len(content)-1], tt.wantNotice)
+				}
+				content = content[:len(content)-1]
+			}
+			if got := describeContent(content); got != tt.want {
+				t.Errorf("content = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+
+	mcpserver "mcp-go-sse-server/pkg/server"
+)
+
+// toolQuota limits calls of one tool per principal to count per window.
+type toolQuota struct {
+	count      int
+	windowName string
+	limiter    *clientRateLimiter
+}
+
+// toolQuotas holds the quotas of --tool-quota, keyed by tool name. The quota
+// under ALL_TOOLS applies to every other tool, counted per tool.
+type toolQuotas map[string]*toolQuota
+
+// parseToolQuotas parses a comma-separated list of tool=count/window, e.g.
+// "echo=60/min,*=600/hour". Windows are s, min or hour.
+func parseToolQuotas(list string) (toolQuotas, error) {
+	quotas := toolQuotas{}
+	for _, entry := range strings.Split(list, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		tool, spec, ok := strings.Cut(entry, "=")
+		countText, windowText, ok2 := strings.Cut(spec, "/")
+		if !ok || !ok2 || tool == "" {
+			return nil, fmt.Errorf("--tool-quota: %q is not tool=count/window", entry)
+		}
+		count, err := strconv.Atoi(countText)
+		if err != nil || count <= 0 {
+			return nil, fmt.Errorf("--tool-quota: invalid count in %q", entry)
+		}
+		var window time.Duration
+		var windowName string
+		switch windowText {
+		case "s", "sec", "second":
+			window, windowName = time.Second, "second"
+		case "m", "min", "minute":
+			window, windowName = time.Minute, "minute"
+		case "h", "hour":
+			window, windowName = time.Hour, "hour"
+		default:
+			return nil, fmt.Errorf("--tool-quota: invalid window in %q, use s, min or hour", entry)
+		}
+		quotas[tool] = &toolQuota{
+			count:      count,
+			windowName: windowName,
+			limiter:    newClientRateLimiter(float64(count)/window.Seconds(), count, "session", nil),
+		}
+	}
+	return quotas, nil
+}
+
+// quotaPrincipal identifies the caller a quota is charged to: the subject of
+// its credentials, or its MCP session when it is anonymous.
+func quotaPrincipal(ctx context.Context) string {
+	if claims, ok := mcpserver.ClaimsFromContext(ctx); ok {
+		if subject, ok := claims["sub"].(string); ok && subject != "" {
+			return "principal:" + subject
+		}
+	}
+	if session := server.ClientSessionFromContext(ctx); session != nil {
+		return "session:" + session.SessionID()
+	}
+	return "anonymous"
+}
+
+// middleware answers calls over quota with a tool error instead of running
+// the tool.
+func (q toolQuotas) middleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		quota, ok := q[request.Params.Name]
+		if !ok {
+			quota, ok = q[ALL_TOOLS]
+		}
+		if ok && !quota.limiter.allow(request.Params.Name+"|"+quotaPrincipal(ctx)) {
+			return mcp.NewToolResultError(fmt.Sprintf(
+				"Quota exceeded for tool %s: %d calls per %s. Try again later.",
+				request.Params.Name, quota.count, quota.windowName)), nil
+		}
+		return next(ctx, request)
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	mcpserver "mcp-go-sse-server/pkg/server"
+)
+
+// callWithQuota calls tool through the quota middleware as subject and
+// reports whether the tool ran.
+func callWithQuota(t *testing.T, quotas toolQuotas, subject string, tool string) bool {
+	t.Helper()
+	ctx := mcpserver.WithClaims(context.Background(), map[string]any{"sub": subject})
+	request := mcp.CallToolRequest{}
+	request.Params.Name = tool
+	ran := false
+	result, err := quotas.middleware(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		ran = true
+		return &mcp.CallToolResult{}, nil
+	})(ctx, request)
+	if err != nil {
+		t.Fatalf("middleware returned error: %v", err)
+	}
+	if !ran && !result.IsError {
+		t.Fatal("refused call did not return a tool error")
+	}
+	return ran
+}
+
+func TestToolQuotaDecisions(t *testing.T) {
+	quotas, err := parseToolQuotas("echo=2/hour,*=3/hour")
+	if err != nil {
+		t.Fatal(err)
+	}
+	steps := []struct {
+		subject string
+		tool    string
+		wantRan bool
+	}{
+		{"alice", "echo", true},
+		{"alice", "echo", true},
+		{"alice", "echo", false},
+		// Quotas are per principal.
+		{"bob", "echo", true},
+		// The * quota is counted per tool.
+		{"alice", "add", true},
+		{"alice", "add", true},
+		{"alice", "add", true},
+		{"alice", "add", false},
+		{"alice", "getTinyImage", true},
+		// echo has its own quota, not the * one.
+		{"bob", "echo", true},
+		{"bob", "echo", false},
+	}
+	for i, step := range steps {
+		if ran := callWithQuota(t, quotas, step.subject, step.tool); ran != step.wantRan {
+			t.Errorf("step %d: %s calling %s ran = %v, want %v", i, step.subject, step.tool, ran, step.wantRan)
+		}
+	}
+}
+
+func TestToolQuotaUnlistedToolIsUnlimited(t *testing.T) {
+	quotas, err := parseToolQuotas("echo=1/min")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 5; i++ {
+		if !callWithQuota(t, quotas, "alice", "add") {
+			t.Fatalf("call %d of an unlisted tool was refused", i)
+		}
+	}
+}
+
+func TestToolQuotaBucketsOutliveTheirWindow(t *testing.T) {
+	quotas, err := parseToolQuotas("echo=10/hour")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A bucket dropped before it refills would hand its principal a fresh
+	// quota.
+	if ttl := quotas["echo"].limiter.idleTTL; ttl < time.Hour {
+		t.Errorf("idle TTL = %s, want at least the one hour window", ttl)
+	}
+}
+
+func TestParseToolQuotasErrors(t *testing.T) {
+	for _, list := range []string{"echo", "echo=10", "echo=ten/min", "echo=0/min", "echo=-1/min", "echo=10/day", "=10/min"} {
+		if _, err := parseToolQuotas(list); err == nil {
+			t.Errorf("parseToolQuotas(%q) succeeded", list)
+		}
+	}
+}
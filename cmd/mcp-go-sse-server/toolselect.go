@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// selectTools removes the tools not in the comma-separated enable list, when
+// it is set, and then those in the disable list. Removed tools are neither
+// listed nor callable. Unknown names are rejected so typos do not silently
+// expose a tool.
+func selectTools(mcpServer *server.MCPServer, enable string, disable string) error {
+	registered := mcpServer.ListTools()
+	parse := func(flagName string, list string) (map[string]bool, error) {
+		names := map[string]bool{}
+		for _, name := range strings.Split(list, ",") {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+			if _, ok := registered[name]; !ok {
+				return nil, fmt.Errorf("%s: unknown tool %q", flagName, name)
+			}
+			names[name] = true
+		}
+		return names, nil
+	}
+	enabled, err := parse("--enable-tools", enable)
+	if err != nil {
+		return err
+	}
+	disabled, err := parse("--disable-tools", disable)
+	if err != nil {
+		return err
+	}
+	var removed []string
+	for name := range registered {
+		if (len(enabled) > 0 && !enabled[name]) || disabled[name] {
+			removed = append(removed, name)
+		}
+	}
+	mcpServer.DeleteTools(removed...)
+	return nil
+}
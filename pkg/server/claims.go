@@ -0,0 +1,20 @@
+package server
+
+import "context"
+
+type claimsKey struct{}
+
+// WithClaims returns a copy of ctx carrying the claims of the caller's
+// validated token.
+func WithClaims(ctx context.Context, claims map[string]any) context.Context {
+	return context.WithValue(ctx, claimsKey{}, claims)
+}
+
+// ClaimsFromContext returns the token claims of the caller, if the request
+// was authenticated with a JWT, or {"sub": user} for HTTP Basic auth and
+// {"sub": "key-<sha256 prefix>"} for API keys. Tool
+// handlers use it for per-user decisions, e.g. claims["sub"].
+func ClaimsFromContext(ctx context.Context) (map[string]any, bool) {
+	claims, ok := ctx.Value(claimsKey{}).(map[string]any)
+	return claims, ok
+}
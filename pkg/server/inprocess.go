@@ -0,0 +1,39 @@
+package server
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// NewInProcessClient returns an initialized client wired to mcpServer through
+// in-memory pipes, without a process or a port. A nil mcpServer gets a fresh
+// server from NewMCPServer. ctx bounds the initialize handshake; the session
+// lives until the caller closes the client.
+func NewInProcessClient(ctx context.Context, mcpServer *server.MCPServer) (*client.Client, error) {
+	if mcpServer == nil {
+		mcpServer = NewMCPServer(nil)
+	}
+	c, err := client.NewInProcessClient(mcpServer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create in-process client: %w", err)
+	}
+	if err := c.Start(context.WithoutCancel(ctx)); err != nil {
+		c.Close()
+		return nil, fmt.Errorf("failed to start in-process client: %w", err)
+	}
+	initRequest := mcp.InitializeRequest{}
+	initRequest.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	initRequest.Params.ClientInfo = mcp.Implementation{
+		Name:    "mcp-go-sse-server-inprocess",
+		Version: "1.0.0",
+	}
+	if _, err := c.Initialize(ctx, initRequest); err != nil {
+		c.Close()
+		return nil, fmt.Errorf("failed to initialize: %w", err)
+	}
+	return c, nil
+}
@@ -0,0 +1,185 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"sync/atomic"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// REDACTED replaces sensitive values unless the rules set another
+// replacement.
+const REDACTED = "[REDACTED]"
+
+// builtinRedactPatterns can be referenced by name in RedactRule without a
+// pattern.
+var builtinRedactPatterns = map[string]string{
+	"email":        `[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`,
+	"card_number":  `\b(?:\d[ -]?){12,18}\d\b`,
+	"jwt":          `\beyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`,
+	"bearer_token": `(?i)\bbearer\s+[A-Za-z0-9._~+/=-]+`,
+}
+
+// RedactRule matches sensitive text. Pattern may be omitted for the built-in
+// rules email, card_number, jwt and bearer_token.
+type RedactRule struct {
+	Name    string `json:"name"`
+	Pattern string `json:"pattern,omitempty"`
+}
+
+// RedactRules configures a Redactor. Keys are field names, such as password
+// or card_number, whose values are replaced whole in structured data.
+type RedactRules struct {
+	Patterns    []RedactRule `json:"patterns"`
+	Keys        []string     `json:"keys,omitempty"`
+	Replacement string       `json:"replacement,omitempty"`
+}
+
+// Redactor masks sensitive data in tool results and logs.
+type Redactor struct {
+	patterns    []*regexp.Regexp
+	keys        map[string]bool
+	replacement string
+}
+
+// NewRedactor compiles rules.
+func NewRedactor(rules RedactRules) (*Redactor, error) {
+	r := &Redactor{keys: map[string]bool{}, replacement: rules.Replacement}
+	if r.replacement == "" {
+		r.replacement = REDACTED
+	}
+	for _, rule := range rules.Patterns {
+		pattern := rule.Pattern
+		if pattern == "" {
+			builtin, ok := builtinRedactPatterns[rule.Name]
+			if !ok {
+				return nil, fmt.Errorf("redact rule %q: missing pattern", rule.Name)
+			}
+			pattern = builtin
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("redact rule %q: %w", rule.Name, err)
+		}
+		r.patterns = append(r.patterns, re)
+	}
+	for _, key := range rules.Keys {
+		r.keys[strings.ToLower(key)] = true
+	}
+	return r, nil
+}
+
+// Redact masks every match of the patterns in s. A nil Redactor returns s.
+func (r *Redactor) Redact(s string) string {
+	if r == nil {
+		return s
+	}
+	for _, re := range r.patterns {
+		s = re.ReplaceAllString(s, r.replacement)
+	}
+	return s
+}
+
+// RedactValue returns a copy of v, as decoded from JSON, with string values
+// masked and the values of sensitive keys replaced whole.
+func (r *Redactor) RedactValue(v any) any {
+	if r == nil {
+		return v
+	}
+	switch v := v.(type) {
+	case string:
+		return r.Redact(v)
+	case map[string]any:
+		redacted := make(map[string]any, len(v))
+		for key, value := range v {
+			if r.keys[strings.ToLower(key)] {
+				redacted[key] = r.replacement
+			} else {
+				redacted[key] = r.RedactValue(value)
+			}
+		}
+		return redacted
+	case []any:
+		redacted := make([]any, len(v))
+		for i, value := range v {
+			redacted[i] = r.RedactValue(value)
+		}
+		return redacted
+	default:
+		return v
+	}
+}
+
+// RedactToolResult masks the text content and structured content of result
+// in place.
+func (r *Redactor) RedactToolResult(result *mcp.CallToolResult) {
+	if r == nil || result == nil {
+		return
+	}
+	for i, content := range result.Content {
+		switch content := content.(type) {
+		case mcp.TextContent:
+			content.Text = r.Redact(content.Text)
+			result.Content[i] = content
+		case *mcp.TextContent:
+			content.Text = r.Redact(content.Text)
+		}
+	}
+	if result.StructuredContent != nil {
+		result.StructuredContent = r.RedactValue(result.StructuredContent)
+	}
+}
+
+var logRedactor atomic.Pointer[Redactor]
+
+// SetLogRedactor makes the logging hooks of every server built by
+// NewMCPServer mask their output with r. Pass nil to log unmasked.
+func SetLogRedactor(r *Redactor) {
+	logRedactor.Store(r)
+}
+
+// logf prints a hook log line. Each argument is logged as its JSON encoding,
+// so request headers, which carry credentials, are always left out; with a
+// log redactor set, the encoding is masked as well.
+func logf(format string, args ...any) {
+	r := logRedactor.Load()
+	encoded := make([]any, len(args))
+	for i, arg := range args {
+		switch arg := arg.(type) {
+		case mcp.MCPMethod:
+			encoded[i] = arg
+		case error:
+			encoded[i] = r.Redact(arg.Error())
+		default:
+			encoded[i] = r.redactJSON(arg)
+		}
+	}
+	fmt.Print(r.Redact(fmt.Sprintf(format, encoded...)))
+}
+
+// UNLOGGABLE stands in for hook log arguments that cannot be encoded.
+const UNLOGGABLE = "[unloggable]"
+
+// redactJSON returns the JSON encoding of v with RedactValue applied. A nil
+// Redactor returns the plain encoding.
+func (r *Redactor) redactJSON(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		log.Printf("Failed to encode log argument: %v", err)
+		return UNLOGGABLE
+	}
+	var decoded any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return r.Redact(string(data))
+	}
+	redacted, err := json.Marshal(r.RedactValue(decoded))
+	if err != nil {
+		log.Printf("Failed to encode redacted log argument: %v", err)
+		return UNLOGGABLE
+	}
+	return string(redacted)
+}